@@ -38,7 +38,7 @@ func (r Changelog) Execute(_ []string) error {
 		return fmt.Errorf("prepare engine: %w", err)
 	}
 
-	taskService, err := r.Task.Build(ctx)
+	taskService, err := r.Task.Build(ctx, r.Engine)
 	if err != nil {
 		return fmt.Errorf("prepare task service: %w", err)
 	}
@@ -48,11 +48,19 @@ func (r Changelog) Execute(_ []string) error {
 		return fmt.Errorf("read release notes builder config: %w", err)
 	}
 
+	if err = r.Notify.checkTemplates(rnbCfg.Destinations); err != nil {
+		return fmt.Errorf("check release notes builder config: %w", err)
+	}
+
 	rnbEvaler := &eval.Evaluator{
 		Addon: eval.MultiAddon{
 			&eval.Git{Engine: gitEngine},
 			&eval.Task{Tracker: taskService},
-			&notes.EvalAddon{TaskTracker: taskService},
+			&notes.EvalAddon{
+				TaskTracker: taskService,
+				// issues are closed by the keywords in pull requests' bodies
+				ClosingRefs: r.Task.Type == "github-issues" || r.Task.Type == "gitlab-issues",
+			},
 		},
 	}
 
@@ -60,6 +68,12 @@ func (r Changelog) Execute(_ []string) error {
 		return fmt.Errorf("release notes template is invalid: %w", err)
 	}
 
+	for name, tmpl := range rnbCfg.Templates {
+		if err = rnbEvaler.Validate(tmpl); err != nil {
+			return fmt.Errorf("release notes template %q is invalid: %w", name, err)
+		}
+	}
+
 	rnb, err := notes.NewBuilder(rnbCfg, rnbEvaler, r.Extras)
 	if err != nil {
 		return fmt.Errorf("prepare release notes builder: %w", err)
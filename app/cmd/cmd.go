@@ -7,35 +7,65 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
 	gengine "github.com/Semior001/releaseit/app/git/engine"
+	"github.com/Semior001/releaseit/app/git/ghclient"
 	"github.com/Semior001/releaseit/app/notify"
+	"github.com/Semior001/releaseit/app/service/eval"
 	tengine "github.com/Semior001/releaseit/app/task/engine"
+	"github.com/samber/lo"
 )
 
 // EngineGroup defines parameters for the engine.
 type EngineGroup struct {
-	Type   string      `long:"type" env:"TYPE" choice:"github" choice:"gitlab" description:"type of the repository engine" required:"true"`
-	Github GithubGroup `group:"github" namespace:"github" env-namespace:"GITHUB"`
-	Gitlab GitlabGroup `group:"gitlab" namespace:"gitlab" env-namespace:"GITLAB"`
+	Type      string         `long:"type" env:"TYPE" choice:"github" choice:"gitlab" choice:"gitea" choice:"bitbucket" choice:"azure" choice:"local" description:"type of the repository engine" required:"true"`
+	Github    GithubGroup    `group:"github" namespace:"github" env-namespace:"GITHUB"`
+	Gitlab    GitlabGroup    `group:"gitlab" namespace:"gitlab" env-namespace:"GITLAB"`
+	Gitea     GiteaGroup     `group:"gitea" namespace:"gitea" env-namespace:"GITEA"`
+	Bitbucket BitbucketGroup `group:"bitbucket" namespace:"bitbucket" env-namespace:"BITBUCKET"`
+	Azure     AzureGroup     `group:"azure" namespace:"azure" env-namespace:"AZURE"`
+	Local     LocalGroup     `group:"local" namespace:"local" env-namespace:"LOCAL"`
+	RateLimit RateLimitGroup `group:"rate-limit" namespace:"rate-limit" env-namespace:"RATE_LIMIT"`
+}
+
+// RateLimitGroup defines parameters to retry requests, failed due to the
+// rate limit or transient server errors, for github and gitlab engines.
+type RateLimitGroup struct {
+	Attempts int           `long:"attempts" env:"ATTEMPTS" description:"maximum number of attempts of the request" default:"5"`
+	Delay    time.Duration `long:"delay" env:"DELAY" description:"delay before the first retry, doubled on each retry" default:"1s"`
+	MaxDelay time.Duration `long:"max-delay" env:"MAX_DELAY" description:"maximum delay between retries, requests aren't retried, if the rate limit is reset later" default:"1m"`
+}
+
+func (g RateLimitGroup) params() gengine.RateLimitParams {
+	return gengine.RateLimitParams{Attempts: g.Attempts, Delay: g.Delay, MaxDelay: g.MaxDelay}
 }
 
 // Build builds the engine.
 func (r EngineGroup) Build(ctx context.Context) (gengine.Interface, error) {
 	switch r.Type {
 	case "github":
-		if err := r.Github.fill(); err != nil {
+		if err := r.Github.Repo.fill(); err != nil {
+			return nil, err
+		}
+
+		app, err := r.Github.app()
+		if err != nil {
 			return nil, err
 		}
 
 		return gengine.NewGithub(ctx, gengine.GithubParams{
 			Owner:             r.Github.Repo.Owner,
 			Name:              r.Github.Repo.Name,
+			BaseURL:           r.Github.BaseURL,
 			BasicAuthUsername: r.Github.BasicAuth.Username,
 			BasicAuthPassword: r.Github.BasicAuth.Password,
+			Token:             r.Github.Token,
+			App:               app,
 			HTTPClient:        http.Client{Timeout: r.Github.Timeout},
+			RateLimit:         r.RateLimit.params(),
 		})
 	case "gitlab":
 		return gengine.NewGitlab(ctx,
@@ -43,25 +73,91 @@ func (r EngineGroup) Build(ctx context.Context) (gengine.Interface, error) {
 			r.Gitlab.BaseURL,
 			r.Gitlab.ProjectID,
 			http.Client{Timeout: r.Gitlab.Timeout},
+			r.RateLimit.params(),
 		)
+	case "gitea":
+		if err := r.Gitea.Repo.fill(); err != nil {
+			return nil, err
+		}
+
+		return gengine.NewGitea(ctx, gengine.GiteaParams{
+			BaseURL:    r.Gitea.BaseURL,
+			Token:      r.Gitea.Token,
+			Owner:      r.Gitea.Repo.Owner,
+			Name:       r.Gitea.Repo.Name,
+			HTTPClient: http.Client{Timeout: r.Gitea.Timeout},
+		})
+	case "bitbucket":
+		return gengine.NewBitbucket(ctx, gengine.BitbucketParams{
+			BaseURL:    r.Bitbucket.BaseURL,
+			Token:      r.Bitbucket.Token,
+			ProjectKey: r.Bitbucket.ProjectKey,
+			RepoSlug:   r.Bitbucket.RepoSlug,
+			HTTPClient: http.Client{Timeout: r.Bitbucket.Timeout},
+		})
+	case "azure":
+		return gengine.NewAzure(ctx, gengine.AzureParams{
+			BaseURL:      r.Azure.BaseURL,
+			Organization: r.Azure.Organization,
+			Project:      r.Azure.Project,
+			Repository:   r.Azure.Repository,
+			Token:        r.Azure.Token,
+			HTTPClient:   http.Client{Timeout: r.Azure.Timeout},
+		})
+	case "local":
+		params := gengine.LocalParams{Path: r.Local.Path, Remote: r.Local.Remote}
+		if r.Local.PREngine != "" {
+			remote := r
+			remote.Type = r.Local.PREngine
+
+			var err error
+			if params.PRs, err = remote.Build(ctx); err != nil {
+				return nil, fmt.Errorf("build %s engine to look up pull requests: %w", r.Local.PREngine, err)
+			}
+		}
+		return gengine.NewLocal(ctx, params)
 	}
 	return nil, fmt.Errorf("unsupported repository engine type %s", r.Type)
 }
 
 // TaskGroup defines parameters for task service
 type TaskGroup struct {
-	Type string `long:"type" env:"TYPE" choice:"" choice:"jira" description:"type of the task tracker"`
-	Jira Jira   `group:"jira" namespace:"jira" env-namespace:"JIRA"`
+	Type     string   `long:"type" env:"TYPE" choice:"" choice:"jira" choice:"youtrack" choice:"linear" choice:"github-issues" choice:"gitlab-issues" description:"type of the task tracker, github-issues and gitlab-issues use credentials of the repository engine"`
+	Jira     Jira     `group:"jira" namespace:"jira" env-namespace:"JIRA"`
+	YouTrack YouTrack `group:"youtrack" namespace:"youtrack" env-namespace:"YOUTRACK"`
+	Linear   Linear   `group:"linear" namespace:"linear" env-namespace:"LINEAR"`
 }
 
-// Build builds the task service.
-func (r TaskGroup) Build(ctx context.Context) (_ *tengine.Tracker, err error) {
+// Build builds the task service, repository engine parameters are used
+// to access issues of the repository.
+func (r TaskGroup) Build(ctx context.Context, repo EngineGroup) (_ *tengine.Tracker, err error) {
 	var eng tengine.Interface
 	switch r.Type {
 	case "jira":
 		if eng, err = r.Jira.Build(ctx); err != nil {
 			return nil, fmt.Errorf("build jira task tracker: %w", err)
 		}
+	case "youtrack":
+		if eng, err = r.YouTrack.Build(ctx); err != nil {
+			return nil, fmt.Errorf("build youtrack task tracker: %w", err)
+		}
+	case "linear":
+		if eng, err = r.Linear.Build(ctx); err != nil {
+			return nil, fmt.Errorf("build linear task tracker: %w", err)
+		}
+	case "github-issues":
+		if eng, err = r.githubIssues(ctx, repo.Github); err != nil {
+			return nil, fmt.Errorf("build github issues task tracker: %w", err)
+		}
+	case "gitlab-issues":
+		if eng, err = tengine.NewGitlabIssues(ctx, tengine.GitlabIssuesParams{
+			Token:      repo.Gitlab.Token,
+			BaseURL:    repo.Gitlab.BaseURL,
+			ProjectID:  repo.Gitlab.ProjectID,
+			HTTPClient: http.Client{Timeout: repo.Gitlab.Timeout},
+		}); err != nil {
+			return nil, fmt.Errorf("build gitlab issues task tracker: %w", err)
+		}
 	case "":
 		eng = &tengine.Unsupported{}
 	default:
@@ -70,10 +166,34 @@ func (r TaskGroup) Build(ctx context.Context) (_ *tengine.Tracker, err error) {
 	return &tengine.Tracker{Interface: eng}, nil
 }
 
+func (r TaskGroup) githubIssues(ctx context.Context, gh GithubGroup) (tengine.Interface, error) {
+	if err := gh.Repo.fill(); err != nil {
+		return nil, err
+	}
+
+	app, err := gh.app()
+	if err != nil {
+		return nil, err
+	}
+
+	return tengine.NewGithubIssues(ctx, tengine.GithubIssuesParams{
+		Owner:             gh.Repo.Owner,
+		Name:              gh.Repo.Name,
+		BaseURL:           gh.BaseURL,
+		BasicAuthUsername: gh.BasicAuth.Username,
+		BasicAuthPassword: gh.BasicAuth.Password,
+		Token:             gh.Token,
+		App:               app,
+		HTTPClient:        http.Client{Timeout: gh.Timeout},
+	})
+}
+
 // Jira defines parameters for the jira task tracker.
 type Jira struct {
 	BaseURL  string        `long:"base-url" env:"BASE_URL" description:"url of the jira instance"`
-	Token    string        `long:"token" env:"TOKEN" description:"token to connect to the jira instance"`
+	Token    string        `long:"token" env:"TOKEN" description:"token to connect to the jira instance, api token for jira cloud"`
+	Email    string        `long:"email" env:"EMAIL" description:"email of the api token owner, jira cloud only"`
+	Cloud    bool          `long:"cloud" env:"CLOUD" description:"connect to jira cloud with basic auth and v3 api"`
 	Timeout  time.Duration `long:"timeout" env:"TIMEOUT" description:"timeout for http requests" default:"5s"`
 	Enricher struct {
 		LoadWatchers bool `long:"load-watchers" env:"LOAD_WATCHERS" description:"load watchers for the issue"`
@@ -85,37 +205,104 @@ func (r Jira) Build(ctx context.Context) (tengine.Interface, error) {
 	params := tengine.JiraParams{
 		BaseURL:    r.BaseURL,
 		Token:      r.Token,
+		Email:      r.Email,
+		Cloud:      r.Cloud,
 		HTTPClient: http.Client{Timeout: r.Timeout},
 	}
 	params.Enricher.LoadWatchers = r.Enricher.LoadWatchers
 	return tengine.NewJira(ctx, params)
 }
 
+// YouTrack defines parameters for the youtrack task tracker.
+type YouTrack struct {
+	BaseURL       string        `long:"base-url" env:"BASE_URL" description:"url of the youtrack instance"`
+	Token         string        `long:"token" env:"TOKEN" description:"permanent token to connect to the youtrack instance"`
+	AssigneeField string        `long:"assignee-field" env:"ASSIGNEE_FIELD" description:"name of the custom field with the assignee" default:"Assignee"`
+	TypeField     string        `long:"type-field" env:"TYPE_FIELD" description:"name of the custom field with the issue type" default:"Type"`
+	Timeout       time.Duration `long:"timeout" env:"TIMEOUT" description:"timeout for http requests" default:"5s"`
+}
+
+// Build builds the youtrack engine.
+func (r YouTrack) Build(ctx context.Context) (tengine.Interface, error) {
+	return tengine.NewYouTrack(ctx, tengine.YouTrackParams{
+		BaseURL:       r.BaseURL,
+		Token:         r.Token,
+		HTTPClient:    http.Client{Timeout: r.Timeout},
+		AssigneeField: r.AssigneeField,
+		TypeField:     r.TypeField,
+	})
+}
+
+// Linear defines parameters for the linear task tracker.
+type Linear struct {
+	BaseURL string        `long:"base-url" env:"BASE_URL" description:"url of the linear api" default:"https://api.linear.app"`
+	Token   string        `long:"token" env:"TOKEN" description:"personal api key, oauth tokens must be prefixed with 'Bearer '"`
+	Timeout time.Duration `long:"timeout" env:"TIMEOUT" description:"timeout for http requests" default:"5s"`
+}
+
+// Build builds the linear engine.
+func (r Linear) Build(ctx context.Context) (tengine.Interface, error) {
+	return tengine.NewLinear(ctx, tengine.LinearParams{
+		BaseURL:    r.BaseURL,
+		Token:      r.Token,
+		HTTPClient: http.Client{Timeout: r.Timeout},
+	})
+}
+
+// RepoGroup defines parameters to locate the repository by its owner and name.
+type RepoGroup struct {
+	FullName string `long:"full-name" env:"FULL_NAME" description:"full name of the repository (owner/name)"`
+	Owner    string `long:"owner" env:"OWNER" description:"owner of the repository"`
+	Name     string `long:"name" env:"NAME" description:"name of the repository"`
+}
+
+func (g *RepoGroup) fill() error {
+	if g.FullName == "" || (g.Owner != "" && g.Name != "") {
+		return nil
+	}
+
+	tokens := strings.Split(g.FullName, "/")
+	if len(tokens) != 2 {
+		return fmt.Errorf("invalid repository name %s", g.FullName)
+	}
+	g.Owner, g.Name = tokens[0], tokens[1]
+	return nil
+}
+
+func (g RepoGroup) empty() bool { return g.FullName == "" && (g.Owner == "" || g.Name == "") }
+
 // GithubGroup defines parameters to connect to the github repository.
 type GithubGroup struct {
-	Repo struct {
-		FullName string `long:"full-name" env:"FULL_NAME" description:"full name of the repository (owner/name)"`
-		Owner    string `long:"owner" env:"OWNER" description:"owner of the repository"`
-		Name     string `long:"name" env:"NAME" description:"name of the repository"`
-	} `group:"repo" namespace:"repo" env-namespace:"REPO"`
+	Repo      RepoGroup `group:"repo" namespace:"repo" env-namespace:"REPO"`
+	BaseURL   string    `long:"base-url" env:"BASE_URL" description:"api url of the github enterprise server instance"`
+	Token     string    `long:"token" env:"TOKEN" description:"personal access token"`
 	BasicAuth struct {
 		Username string `long:"username" env:"USERNAME" description:"username for basic auth"`
 		Password string `long:"password" env:"PASSWORD" description:"password for basic auth"`
 	} `group:"basic-auth" namespace:"basic-auth" env-namespace:"BASIC_AUTH"`
+	App struct {
+		ID             int64  `long:"id" env:"ID" description:"id of the github app"`
+		InstallationID int64  `long:"installation-id" env:"INSTALLATION_ID" description:"id of the app installation, looked up by repository if not set"`
+		PrivateKey     string `long:"private-key" env:"PRIVATE_KEY" description:"PEM-encoded private key of the app or path to the file with it"`
+	} `group:"app" namespace:"app" env-namespace:"APP"`
 	Timeout time.Duration `long:"timeout" env:"TIMEOUT" description:"timeout for http requests" default:"5s"`
 }
 
-func (g *GithubGroup) fill() error {
-	if g.Repo.FullName == "" || (g.Repo.Owner != "" && g.Repo.Name != "") {
-		return nil
+// app returns parameters of the github app, reading the private key from file, if needed.
+func (g GithubGroup) app() (ghclient.AppParams, error) {
+	if g.App.ID == 0 {
+		return ghclient.AppParams{}, nil
 	}
 
-	tokens := strings.Split(g.Repo.FullName, "/")
-	if len(tokens) != 2 {
-		return fmt.Errorf("invalid repository name %s", g.Repo.FullName)
+	key := []byte(g.App.PrivateKey)
+	if !strings.HasPrefix(strings.TrimSpace(g.App.PrivateKey), "-----BEGIN") {
+		var err error
+		if key, err = os.ReadFile(g.App.PrivateKey); err != nil {
+			return ghclient.AppParams{}, fmt.Errorf("read github app private key: %w", err)
+		}
 	}
-	g.Repo.Owner, g.Repo.Name = tokens[0], tokens[1]
-	return nil
+
+	return ghclient.AppParams{ID: g.App.ID, InstallationID: g.App.InstallationID, PrivateKey: key}, nil
 }
 
 // GitlabGroup defines parameters to connect to the gitlab repository.
@@ -126,13 +313,56 @@ type GitlabGroup struct {
 	Timeout   time.Duration `long:"timeout" env:"TIMEOUT" description:"timeout for http requests" default:"5s"`
 }
 
+// GiteaGroup defines parameters to connect to the gitea (or forgejo) repository.
+type GiteaGroup struct {
+	BaseURL string        `long:"base-url" env:"BASE_URL" description:"base url of the gitea instance"`
+	Token   string        `long:"token" env:"TOKEN" description:"token to connect to the gitea repository"`
+	Repo    RepoGroup     `group:"repo" namespace:"repo" env-namespace:"REPO"`
+	Timeout time.Duration `long:"timeout" env:"TIMEOUT" description:"timeout for http requests" default:"5s"`
+}
+
+// BitbucketGroup defines parameters to connect to the Bitbucket Server (Data Center) repository.
+type BitbucketGroup struct {
+	BaseURL    string        `long:"base-url" env:"BASE_URL" description:"base url of the bitbucket instance"`
+	Token      string        `long:"token" env:"TOKEN" description:"http access token to connect to the bitbucket repository"`
+	ProjectKey string        `long:"project-key" env:"PROJECT_KEY" description:"key of the project"`
+	RepoSlug   string        `long:"repo-slug" env:"REPO_SLUG" description:"slug of the repository"`
+	Timeout    time.Duration `long:"timeout" env:"TIMEOUT" description:"timeout for http requests" default:"5s"`
+}
+
+// AzureGroup defines parameters to connect to the Azure DevOps repository.
+type AzureGroup struct {
+	BaseURL      string        `long:"base-url" env:"BASE_URL" description:"base url of the azure devops instance" default:"https://dev.azure.com"`
+	Organization string        `long:"organization" env:"ORGANIZATION" description:"name of the organization"`
+	Project      string        `long:"project" env:"PROJECT" description:"name of the project"`
+	Repository   string        `long:"repository" env:"REPOSITORY" description:"name or id of the repository"`
+	Token        string        `long:"token" env:"TOKEN" description:"personal access token to connect to the azure devops repository"`
+	Timeout      time.Duration `long:"timeout" env:"TIMEOUT" description:"timeout for http requests" default:"5s"`
+}
+
+// LocalGroup defines parameters to read the on-disk git repository.
+type LocalGroup struct {
+	Path     string `long:"path" env:"PATH" description:"path to the git repository" default:"."`
+	Remote   string `long:"remote" env:"REMOTE" description:"remote to look up the branch in, if there is no local one" default:"origin"`
+	PREngine string `long:"pr-engine" env:"PR_ENGINE" choice:"" choice:"github" choice:"gitlab" choice:"gitea" choice:"bitbucket" choice:"azure" description:"remote engine to look up pull requests, if empty, pull requests are derived from merge commit messages"`
+}
+
 // NotifyGroup defines parameters for the notifier.
 type NotifyGroup struct {
 	Telegram      TelegramGroup       `group:"telegram" namespace:"telegram" env-namespace:"TELEGRAM"`
 	Github        GithubNotifierGroup `group:"github" namespace:"github" env-namespace:"GITHUB"`
+	Gitlab        GitlabNotifierGroup `group:"gitlab" namespace:"gitlab" env-namespace:"GITLAB"`
 	Mattermost    MattermostHookGroup `group:"mattermost-hook" namespace:"mattermost-hook" env-namespace:"MATTERMOST_HOOK"`
 	MattermostBot MattermostBotGroup  `group:"mattermost-bot" namespace:"mattermost-bot" env-namespace:"MATTERMOST_BOT"`
+	Slack         SlackGroup          `group:"slack" namespace:"slack" env-namespace:"SLACK"`
+	Teams         TeamsGroup          `group:"teams" namespace:"teams" env-namespace:"TEAMS"`
+	Discord       DiscordGroup        `group:"discord" namespace:"discord" env-namespace:"DISCORD"`
+	Matrix        MatrixGroup         `group:"matrix" namespace:"matrix" env-namespace:"MATRIX"`
+	Email         EmailGroup          `group:"email" namespace:"email" env-namespace:"EMAIL"`
+	File          FileGroup           `group:"file" namespace:"file" env-namespace:"FILE"`
 	Post          PostGroup           `group:"post" namespace:"post" env-namespace:"POST"`
+	Webhook       WebhookGroup        `group:"webhook" namespace:"webhook" env-namespace:"WEBHOOK"`
+	Retry         RetryGroup          `group:"retry" namespace:"retry" env-namespace:"RETRY"`
 	Stdout        bool                `long:"stdout" env:"STDOUT" description:"print release notes to stdout"`
 	Stderr        bool                `long:"stderr" env:"STDERR" description:"print release notes to stderr"`
 }
@@ -141,23 +371,82 @@ type NotifyGroup struct {
 type GithubNotifierGroup struct {
 	GithubGroup
 	ReleaseNameTemplate string            `long:"release-name-tmpl" env:"RELEASE_NAME_TMPL" description:"template for release name"`
-	Tag                 string            `long:"tag" env:"TAG" description:"tag to specify release"`
+	Tag                 string            `long:"tag" env:"TAG" description:"tag to specify release, the version of the release by default"`
 	Extras              map[string]string `long:"extra" env:"EXTRA" description:"extra parameters to pass to the notifier"`
+	Update              string            `long:"update" env:"UPDATE" choice:"none" choice:"replace" choice:"append" default:"none" description:"update the existing release for the tag by replacing or appending to its body"`
+	Draft               bool              `long:"draft" env:"DRAFT" description:"mark the new release as a draft, the existing one is left as is"`
+	Prerelease          string            `long:"prerelease" env:"PRERELEASE" choice:"auto" choice:"true" choice:"false" default:"auto" description:"mark the release as a prerelease, auto - the new one if the tag has a semver pre-release suffix"`
+	MakeLatest          string            `long:"make-latest" env:"MAKE_LATEST" choice:"true" choice:"false" choice:"legacy" description:"set the release as the latest one"`
 }
 
 func (g GithubNotifierGroup) build() (notify.Destination, error) {
-	if err := g.GithubGroup.fill(); err != nil {
+	if err := g.Repo.fill(); err != nil {
+		return nil, err
+	}
+
+	var prerelease *bool
+	if g.Prerelease != "auto" {
+		prerelease = lo.ToPtr(g.Prerelease == "true")
+	}
+
+	update := notify.GithubUpdateMode(g.Update)
+	if g.Update == "none" {
+		update = notify.GithubUpdateNone
+	}
+
+	app, err := g.app()
+	if err != nil {
 		return nil, err
 	}
 
 	return notify.NewGithub(notify.GithubParams{
+		Evaluator:           &eval.Evaluator{},
 		Owner:               g.Repo.Owner,
 		Name:                g.Repo.Name,
+		BaseURL:             g.BaseURL,
 		BasicAuthUsername:   g.BasicAuth.Username,
 		BasicAuthPassword:   g.BasicAuth.Password,
+		Token:               g.Token,
+		App:                 app,
+		HTTPClient:          http.Client{Timeout: g.Timeout},
+		ReleaseNameTmplText: g.ReleaseNameTemplate,
+		Tag:                 g.Tag,
+		Extras:              g.Extras,
+		Update:              update,
+		Draft:               g.Draft,
+		Prerelease:          prerelease,
+		MakeLatest:          g.MakeLatest,
+	})
+}
+
+// GitlabNotifierGroup defines parameters to make release in the gitlab.
+type GitlabNotifierGroup struct {
+	GitlabGroup
+	ReleaseNameTemplate string            `long:"release-name-tmpl" env:"RELEASE_NAME_TMPL" description:"template for release name"`
+	Tag                 string            `long:"tag" env:"TAG" description:"tag to specify release, the version of the release by default"`
+	Milestones          []string          `long:"milestone" env:"MILESTONE" env-delim:"," description:"title of the milestone to associate the release with"`
+	AssetLinks          map[string]string `long:"asset-link" env:"ASSET_LINK" env-delim:"," description:"link to attach to the release, in format name:url"`
+	Extras              map[string]string `long:"extra" env:"EXTRA" description:"extra parameters to pass to the notifier"`
+}
+
+func (g GitlabNotifierGroup) build() (notify.Destination, error) {
+	names := lo.Keys(g.AssetLinks)
+	sort.Strings(names)
+
+	links := lo.Map(names, func(name string, _ int) notify.GitlabAssetLink {
+		return notify.GitlabAssetLink{Name: name, URL: g.AssetLinks[name]}
+	})
+
+	return notify.NewGitlab(notify.GitlabParams{
+		Evaluator:           &eval.Evaluator{},
+		Token:               g.Token,
+		BaseURL:             g.BaseURL,
+		ProjectID:           g.ProjectID,
 		HTTPClient:          http.Client{Timeout: g.Timeout},
 		ReleaseNameTmplText: g.ReleaseNameTemplate,
 		Tag:                 g.Tag,
+		Milestones:          g.Milestones,
+		AssetLinks:          links,
 		Extras:              g.Extras,
 	})
 }
@@ -167,6 +456,7 @@ type TelegramGroup struct {
 	ChatID         string        `long:"chat-id" env:"CHAT_ID" description:"id of the chat, where the release notes will be sent"`
 	Token          string        `long:"token" env:"TOKEN" description:"bot token"`
 	WebPagePreview bool          `long:"web-page-preview" env:"WEB_PAGE_PREVIEW" description:"request telegram to preview for web links"`
+	ThreadID       int           `long:"message-thread-id" env:"MESSAGE_THREAD_ID" description:"id of the forum topic, where the release notes will be sent"`
 	Timeout        time.Duration `long:"timeout" env:"TIMEOUT" description:"timeout for http requests" default:"5s"`
 }
 
@@ -174,13 +464,14 @@ func (g TelegramGroup) build() (notify.Destination, error) {
 	lg := cloneLogger(log.Default())
 	lg.SetPrefix("[TG] " + lg.Prefix())
 
-	return notify.NewTelegram(notify.TelegramParams{
+	return notify.Text(notify.NewTelegram(notify.TelegramParams{
 		ChatID:                g.ChatID,
 		Client:                http.Client{Timeout: g.Timeout},
 		Token:                 g.Token,
 		DisableWebPagePreview: !g.WebPagePreview,
+		MessageThreadID:       g.ThreadID,
 		Log:                   lg,
-	}), nil
+	})), nil
 }
 
 // MattermostHookGroup defines parameters for mattermost hook notifier.
@@ -196,7 +487,7 @@ func (g MattermostHookGroup) build() (notify.Destination, error) {
 		lg := cloneLogger(log.Default())
 		lg.SetPrefix("[MM_HOOK|" + fmt.Sprint(idx) + "] " + lg.Prefix())
 		dest := notify.NewMattermost(lg, http.Client{Timeout: g.Timeout}, u)
-		dests = append(dests, dest)
+		dests = append(dests, notify.Text(dest))
 	}
 
 	return notify.Destinations(dests), nil
@@ -214,7 +505,191 @@ func (g MattermostBotGroup) build() (notify.Destination, error) {
 	lg := cloneLogger(log.Default())
 	lg.SetPrefix("[MM_BOT] " + lg.Prefix())
 
-	return notify.NewMattermostBot(lg, http.Client{Timeout: g.Timeout}, g.BaseURL, g.Token, g.ChannelID)
+	bot, err := notify.NewMattermostBot(lg, http.Client{Timeout: g.Timeout}, g.BaseURL, g.Token, g.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+
+	return notify.Text(bot), nil
+}
+
+// SlackGroup defines parameters for slack notifier.
+type SlackGroup struct {
+	WebhookURL []string      `long:"webhook-url" env:"WEBHOOK_URL" env-delim:"," description:"url of the slack incoming webhook, can take multiple values, delim envs with ','"`
+	Token      string        `long:"token" env:"TOKEN" description:"token of the slack bot"`
+	ChannelID  string        `long:"channel-id" env:"CHANNEL_ID" description:"channel id to post release notes by the bot"`
+	ThreadTS   string        `long:"thread-ts" env:"THREAD_TS" description:"timestamp of the parent message to post release notes by the bot as a thread reply"`
+	Timeout    time.Duration `long:"timeout" env:"TIMEOUT" description:"timeout for http requests" default:"5s"`
+}
+
+func (g SlackGroup) build() (notify.Destination, error) {
+	var dests []notify.Destination
+	for idx, u := range g.WebhookURL {
+		lg := cloneLogger(log.Default())
+		lg.SetPrefix("[SLACK_HOOK|" + fmt.Sprint(idx) + "] " + lg.Prefix())
+		dests = append(dests, notify.Text(notify.NewSlack(lg, http.Client{Timeout: g.Timeout}, u)))
+	}
+
+	if g.Token != "" && g.ChannelID != "" {
+		lg := cloneLogger(log.Default())
+		lg.SetPrefix("[SLACK_BOT] " + lg.Prefix())
+
+		bot, err := notify.NewSlackBot(notify.SlackBotParams{
+			Log:       lg,
+			Client:    http.Client{Timeout: g.Timeout},
+			Token:     g.Token,
+			ChannelID: g.ChannelID,
+			ThreadTS:  g.ThreadTS,
+		})
+		if err != nil {
+			return nil, err
+		}
+		dests = append(dests, notify.Text(bot))
+	}
+
+	return notify.Destinations(dests), nil
+}
+
+// TeamsGroup defines parameters for microsoft teams notifier.
+type TeamsGroup struct {
+	URL     string            `long:"url" env:"URL" description:"url of the teams incoming webhook or workflow"`
+	Title   string            `long:"title" env:"TITLE" description:"title of the card"`
+	Actions map[string]string `long:"action" env:"ACTION" env-delim:"," description:"button to open url under the release notes, in format title:url"`
+	Timeout time.Duration     `long:"timeout" env:"TIMEOUT" description:"timeout for http requests" default:"5s"`
+}
+
+//nolint:unparam // it must match the specific signature
+func (g TeamsGroup) build() (notify.Destination, error) {
+	lg := cloneLogger(log.Default())
+	lg.SetPrefix("[TEAMS] " + lg.Prefix())
+
+	titles := lo.Keys(g.Actions)
+	sort.Strings(titles)
+
+	return notify.Text(notify.NewTeams(notify.TeamsParams{
+		Log:    lg,
+		Client: http.Client{Timeout: g.Timeout},
+		URL:    g.URL,
+		Title:  g.Title,
+		Actions: lo.Map(titles, func(title string, _ int) notify.TeamsAction {
+			return notify.TeamsAction{Title: title, URL: g.Actions[title]}
+		}),
+	})), nil
+}
+
+// DiscordGroup defines parameters for discord notifier.
+type DiscordGroup struct {
+	URL       string        `long:"url" env:"URL" description:"url of the discord webhook"`
+	Username  string        `long:"username" env:"USERNAME" description:"override the default username of the webhook"`
+	Title     string        `long:"title" env:"TITLE" description:"title of the embed"`
+	Color     int           `long:"color" env:"COLOR" description:"color of the embed's border, decimal"`
+	PlainText bool          `long:"plain-text" env:"PLAIN_TEXT" description:"send release notes as a plain message instead of embeds"`
+	Timeout   time.Duration `long:"timeout" env:"TIMEOUT" description:"timeout for http requests" default:"5s"`
+}
+
+//nolint:unparam // it must match the specific signature
+func (g DiscordGroup) build() (notify.Destination, error) {
+	lg := cloneLogger(log.Default())
+	lg.SetPrefix("[DISCORD] " + lg.Prefix())
+
+	return notify.Text(notify.NewDiscord(notify.DiscordParams{
+		Log:       lg,
+		Client:    http.Client{Timeout: g.Timeout},
+		URL:       g.URL,
+		Username:  g.Username,
+		Title:     g.Title,
+		Color:     g.Color,
+		PlainText: g.PlainText,
+	})), nil
+}
+
+// MatrixGroup defines parameters for matrix notifier.
+type MatrixGroup struct {
+	BaseURL string        `long:"base-url" env:"BASE_URL" description:"url of the matrix homeserver"`
+	Token   string        `long:"token" env:"TOKEN" description:"access token of the user to send messages from"`
+	RoomID  string        `long:"room-id" env:"ROOM_ID" description:"id of the room, where the release notes will be sent"`
+	Notice  bool          `long:"notice" env:"NOTICE" description:"send release notes as m.notice message, intended for bots"`
+	Timeout time.Duration `long:"timeout" env:"TIMEOUT" description:"timeout for http requests" default:"5s"`
+}
+
+//nolint:unparam // it must match the specific signature
+func (g MatrixGroup) build() (notify.Destination, error) {
+	lg := cloneLogger(log.Default())
+	lg.SetPrefix("[MATRIX] " + lg.Prefix())
+
+	return notify.Text(notify.NewMatrix(notify.MatrixParams{
+		Log:     lg,
+		Client:  http.Client{Timeout: g.Timeout},
+		BaseURL: g.BaseURL,
+		Token:   g.Token,
+		RoomID:  g.RoomID,
+		Notice:  g.Notice,
+	})), nil
+}
+
+// EmailGroup defines parameters for email notifier.
+type EmailGroup struct {
+	Host               string            `long:"host" env:"HOST" description:"host of the smtp server"`
+	Port               int               `long:"port" env:"PORT" description:"port of the smtp server" default:"587"`
+	Security           string            `long:"security" env:"SECURITY" choice:"none" choice:"starttls" choice:"tls" description:"connection security, credentials are sent over none only to localhost" default:"starttls"`
+	Username           string            `long:"username" env:"USERNAME" description:"username for smtp authentication, if empty, no authentication is performed"`
+	Password           string            `long:"password" env:"PASSWORD" description:"password for smtp authentication"`
+	From               string            `long:"from" env:"FROM" description:"sender of the email"`
+	To                 []string          `long:"to" env:"TO" env-delim:"," description:"recipient of the email, can take multiple values, delim envs with ','"`
+	SubjectTemplate    string            `long:"subject-tmpl" env:"SUBJECT_TMPL" description:"template for the email subject, has access to the release fields, e.g. .Version or .PRs"`
+	Extras             map[string]string `long:"extra" env:"EXTRA" description:"extra parameters to pass to the subject template"`
+	InsecureSkipVerify bool              `long:"insecure-skip-verify" env:"INSECURE_SKIP_VERIFY" description:"skip verification of the server's certificate"`
+	Timeout            time.Duration     `long:"timeout" env:"TIMEOUT" description:"timeout for smtp connection" default:"10s"`
+}
+
+func (g EmailGroup) build() (notify.Destination, error) {
+	lg := cloneLogger(log.Default())
+	lg.SetPrefix("[EMAIL] " + lg.Prefix())
+
+	email, err := notify.NewEmail(notify.EmailParams{
+		Log:                lg,
+		Evaluator:          &eval.Evaluator{},
+		Host:               g.Host,
+		Port:               g.Port,
+		Security:           notify.EmailSecurity(g.Security),
+		Username:           g.Username,
+		Password:           g.Password,
+		From:               g.From,
+		To:                 g.To,
+		SubjectTmplText:    g.SubjectTemplate,
+		Extras:             g.Extras,
+		Timeout:            g.Timeout,
+		InsecureSkipVerify: g.InsecureSkipVerify,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return email, nil
+}
+
+// FileGroup defines parameters for file notifier.
+type FileGroup struct {
+	Path   string `long:"path" env:"PATH" description:"path to the file to write release notes to"`
+	Mode   string `long:"mode" env:"MODE" choice:"overwrite" choice:"append" choice:"prepend-after-marker" description:"how to write release notes to the file" default:"prepend-after-marker"`
+	Marker string `long:"marker" env:"MARKER" description:"marker, below which release notes are inserted in prepend-after-marker mode" default:"<!-- releaseit -->"`
+}
+
+func (g FileGroup) build() (notify.Destination, error) {
+	lg := cloneLogger(log.Default())
+	lg.SetPrefix("[FILE] " + lg.Prefix())
+
+	file, err := notify.NewFile(notify.FileParams{
+		Log:    lg,
+		Path:   g.Path,
+		Mode:   notify.FileMode(g.Mode),
+		Marker: g.Marker,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return file, nil
 }
 
 // PostGroup defines parameters for post notifier.
@@ -227,33 +702,145 @@ func (g PostGroup) build() (notify.Destination, error) {
 	lg := cloneLogger(log.Default())
 	lg.SetPrefix("[POST] " + lg.Prefix())
 
-	return &notify.Post{
+	return notify.Text(&notify.Post{
 		Log:    lg,
 		URL:    g.URL,
 		Client: &http.Client{Timeout: g.Timeout},
-	}, nil
+	}), nil
 }
 
-// Build builds the notifier.
-func (r *NotifyGroup) Build() (destinations notify.Destinations, err error) {
-	if r.Stdout {
-		destinations = append(destinations, &notify.WriterNotifier{Writer: os.Stdout, Name: "stdout"})
+// WebhookGroup defines parameters for webhook notifier.
+type WebhookGroup struct {
+	URL              string            `long:"url" env:"URL" description:"url of the webhook"`
+	Method           string            `long:"method" env:"METHOD" description:"http method of the request" default:"POST"`
+	Headers          map[string]string `long:"header" env:"HEADER" env-delim:"\n" description:"header to add to the request, in format name:value, can take multiple values, delim envs with new line"`
+	BodyTemplate     string            `long:"body-tmpl" env:"BODY_TMPL" description:"template for the request body, has access to the release fields, e.g. .Text or .Version"`
+	Extras           map[string]string `long:"extra" env:"EXTRA" description:"extra parameters to pass to the body template"`
+	AcceptedStatuses []string          `long:"accepted-status" env:"ACCEPTED_STATUS" env-delim:"," description:"accepted status codes, e.g. 200, 200-204 or 2xx, can take multiple values, delim envs with ','" default:"2xx"`
+	Secret           string            `long:"secret" env:"SECRET" description:"secret to sign the body with HMAC-SHA256"`
+	SignatureHeader  string            `long:"signature-header" env:"SIGNATURE_HEADER" description:"header to put the signature in" default:"X-Signature-256"`
+	Timeout          time.Duration     `long:"timeout" env:"TIMEOUT" description:"timeout for http requests" default:"5s"`
+}
+
+func (g WebhookGroup) build() (notify.Destination, error) {
+	lg := cloneLogger(log.Default())
+	lg.SetPrefix("[WEBHOOK] " + lg.Prefix())
+
+	statuses := make([]notify.StatusRange, len(g.AcceptedStatuses))
+	for i, s := range g.AcceptedStatuses {
+		var err error
+		if statuses[i], err = notify.ParseStatusRange(s); err != nil {
+			return nil, fmt.Errorf("parse accepted status: %w", err)
+		}
 	}
-	if r.Stderr {
-		destinations = append(destinations, &notify.WriterNotifier{Writer: os.Stderr, Name: "stderr"})
+
+	return notify.NewWebhook(notify.WebhookParams{
+		Log:              lg,
+		Client:           http.Client{Timeout: g.Timeout},
+		Evaluator:        &eval.Evaluator{},
+		URL:              g.URL,
+		Method:           g.Method,
+		Headers:          g.Headers,
+		BodyTmplText:     g.BodyTemplate,
+		Extras:           g.Extras,
+		AcceptedStatuses: statuses,
+		Secret:           g.Secret,
+		SignatureHeader:  g.SignatureHeader,
+	})
+}
+
+// RetryGroup defines parameters for retries of sending release notes.
+type RetryGroup struct {
+	Attempts int           `long:"attempts" env:"ATTEMPTS" description:"max attempts to send release notes, including the first one" default:"3"`
+	Delay    time.Duration `long:"delay" env:"DELAY" description:"delay before the first retry, doubled on each next one" default:"1s"`
+	MaxDelay time.Duration `long:"max-delay" env:"MAX_DELAY" description:"max delay between retries" default:"30s"`
+	Jitter   float64       `long:"jitter" env:"JITTER" description:"fraction of the delay to randomize" default:"0.2"`
+}
+
+// wrap wraps the destination with retries, destinations of the group
+// are wrapped one by one, not to resend to the ones, which succeeded.
+// Requests to idempotent destinations are retried on any network error.
+func (g RetryGroup) wrap(dest notify.Destination, idempotent bool) notify.Destination {
+	if g.Attempts <= 1 {
+		return dest
+	}
+
+	if group, ok := dest.(notify.Destinations); ok {
+		return notify.Destinations(lo.Map(group, func(d notify.Destination, _ int) notify.Destination {
+			return g.wrap(d, idempotent)
+		}))
 	}
 
-	for _, d := range []struct {
-		name  string
-		empty bool
-		build func() (notify.Destination, error)
-	}{
+	return notify.NewRetry(dest, notify.RetryParams{
+		Log:      log.Default(),
+		Attempts: g.Attempts,
+		Delay:    g.Delay,
+		MaxDelay: g.MaxDelay,
+		Jitter:   g.Jitter,
+
+		Idempotent: idempotent,
+	})
+}
+
+// notifierBuilder describes how to build the notifier of the group.
+type notifierBuilder struct {
+	name  string
+	empty bool
+	build func() (notify.Destination, error)
+	// idempotent destinations don't duplicate the release, if it's sent twice
+	idempotent bool
+}
+
+// builders lists all notifiers, except stdout and stderr, which might be built.
+func (r *NotifyGroup) builders() []notifierBuilder {
+	return []notifierBuilder{
 		{name: "telegram", empty: r.Telegram.empty(), build: r.Telegram.build},
-		{name: "github", empty: r.Github.empty(), build: r.Github.build},
+		{name: "github", empty: r.Github.empty(), build: r.Github.build, idempotent: r.Github.Update != "none"},
+		{name: "gitlab", empty: r.Gitlab.empty(), build: r.Gitlab.build, idempotent: true},
 		{name: "mattermost-hook", empty: r.Mattermost.empty(), build: r.Mattermost.build},
 		{name: "post", empty: r.Post.empty(), build: r.Post.build},
+		{name: "webhook", empty: r.Webhook.empty(), build: r.Webhook.build},
+		{name: "slack", empty: r.Slack.empty(), build: r.Slack.build},
+		{name: "teams", empty: r.Teams.empty(), build: r.Teams.build},
+		{name: "discord", empty: r.Discord.empty(), build: r.Discord.build},
+		{name: "matrix", empty: r.Matrix.empty(), build: r.Matrix.build, idempotent: true},
+		{name: "email", empty: r.Email.empty(), build: r.Email.build},
+		{name: "file", empty: r.File.empty(), build: r.File.build},
 		{name: "mattermost-bot", empty: r.MattermostBot.empty(), build: r.MattermostBot.build},
-	} {
+	}
+}
+
+// checkTemplates checks that templates are assigned only to the known destinations.
+func (r *NotifyGroup) checkTemplates(assigned map[string]string) error {
+	names := append([]string{"stdout", "stderr"}, lo.Map(r.builders(), func(b notifierBuilder, _ int) string {
+		return b.name
+	})...)
+
+	dests := lo.Keys(assigned)
+	sort.Strings(dests)
+
+	for _, dest := range dests {
+		if !lo.Contains(names, dest) {
+			return fmt.Errorf("template is assigned to unknown destination %q, expected one of: %s",
+				dest, strings.Join(names, ", "))
+		}
+	}
+
+	return nil
+}
+
+// Build builds the notifier.
+func (r *NotifyGroup) Build() (destinations notify.Destinations, err error) {
+	if r.Stdout {
+		destinations = append(destinations, notify.Named("stdout",
+			notify.Text(&notify.WriterNotifier{Writer: os.Stdout, Name: "stdout"})))
+	}
+	if r.Stderr {
+		destinations = append(destinations, notify.Named("stderr",
+			notify.Text(&notify.WriterNotifier{Writer: os.Stderr, Name: "stderr"})))
+	}
+
+	for _, d := range r.builders() {
 		if d.empty {
 			continue
 		}
@@ -262,7 +849,8 @@ func (r *NotifyGroup) Build() (destinations notify.Destinations, err error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to build %s notifier: %w", d.name, err)
 		}
-		destinations = append(destinations, dest)
+
+		destinations = append(destinations, notify.Named(d.name, r.Retry.wrap(dest, d.idempotent)))
 	}
 
 	log.Printf("[INFO] initialized %d notifiers: %s", len(destinations), destinations.String())
@@ -278,9 +866,25 @@ func (g MattermostBotGroup) empty() bool {
 	return g.BaseURL == "" || g.Token == "" || g.ChannelID == ""
 }
 
+func (g MatrixGroup) empty() bool {
+	return g.BaseURL == "" || g.Token == "" || g.RoomID == ""
+}
+
+func (g SlackGroup) empty() bool {
+	return len(g.WebhookURL) == 0 && (g.Token == "" || g.ChannelID == "")
+}
+
+func (g WebhookGroup) empty() bool        { return g.URL == "" }
 func (g PostGroup) empty() bool           { return g.URL == "" }
+func (g TeamsGroup) empty() bool          { return g.URL == "" }
+func (g DiscordGroup) empty() bool        { return g.URL == "" }
+func (g EmailGroup) empty() bool          { return g.Host == "" || len(g.To) == 0 }
+func (g FileGroup) empty() bool           { return g.Path == "" }
 func (g MattermostHookGroup) empty() bool { return len(g.URL) == 0 }
 func (g TelegramGroup) empty() bool       { return g.ChatID == "" || g.Token == "" }
 func (g GithubNotifierGroup) empty() bool {
-	return g.ReleaseNameTemplate == "" || (g.Repo.FullName == "" && (g.Repo.Owner == "" || g.Repo.Name == ""))
+	return g.ReleaseNameTemplate == "" || g.Repo.empty()
+}
+func (g GitlabNotifierGroup) empty() bool {
+	return g.ReleaseNameTemplate == "" || g.ProjectID == ""
 }
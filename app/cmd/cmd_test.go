@@ -2,11 +2,16 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
+	"github.com/Semior001/releaseit/app/notify"
+	"github.com/jessevdk/go-flags"
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -38,7 +43,7 @@ func TestMattermostHookGroup_build(t *testing.T) {
 	dest, err := group.build()
 	require.NoError(t, err)
 
-	err = dest.Send(context.Background(), "test")
+	err = dest.Send(context.Background(), notify.Release{Text: "test"})
 	require.NoError(t, err)
 
 	mu.Lock()
@@ -46,3 +51,113 @@ func TestMattermostHookGroup_build(t *testing.T) {
 
 	assert.Equal(t, []int{1, 1, 1}, called)
 }
+
+func TestRetryGroup_wrap(t *testing.T) {
+	called := make([]int, 3)
+	mu := sync.Mutex{}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		idx, err := strconv.Atoi(r.URL.Query().Get("idx"))
+		require.NoError(t, err)
+
+		// the second hook fails once
+		if called[idx]++; idx == 1 && called[idx] == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer ts.Close()
+
+	group := MattermostHookGroup{
+		URL:     []string{ts.URL + "?idx=0", ts.URL + "?idx=1", ts.URL + "?idx=2"},
+		Timeout: 5 * time.Second,
+	}
+
+	dest, err := group.build()
+	require.NoError(t, err)
+
+	dest = RetryGroup{Attempts: 2, Delay: time.Millisecond}.wrap(dest, false)
+	require.NoError(t, dest.Send(context.Background(), notify.Release{Text: "test"}))
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, []int{1, 2, 1}, called, "only the failed hook must be retried")
+}
+
+func TestGithubNotifierGroup_build(t *testing.T) {
+	var name string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch strings.TrimPrefix(r.URL.Path, "/api/v3") {
+		case "/repos/owner/name":
+			w.WriteHeader(http.StatusOK)
+		case "/repos/owner/name/git/tags/v1.0.0":
+			require.NoError(t, json.NewEncoder(w).Encode(map[string]any{
+				"tag":    "v1.0.0",
+				"object": map[string]any{"sha": "sha", "type": "tag"},
+			}))
+		case "/repos/owner/name/releases":
+			var req struct {
+				Name string `json:"name"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			name = req.Name
+			_, err := w.Write([]byte(`{}`))
+			require.NoError(t, err)
+		default:
+			t.Fatalf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	group := GithubNotifierGroup{ReleaseNameTemplate: "release {{ .Tag.Name }}", Prerelease: "auto", Update: "none"}
+	group.Repo.FullName = "owner/name"
+	group.BaseURL = ts.URL + "/"
+	group.Token = "token"
+	group.Timeout = 5 * time.Second
+
+	dest, err := group.build()
+	require.NoError(t, err)
+
+	// release name template must be evaluated, the evaluator is required for it
+	require.NoError(t, dest.Send(context.Background(), notify.Release{Version: "v1.0.0", Text: "test"}))
+	assert.Equal(t, "release v1.0.0", name)
+}
+
+func TestNotifyGroup_checkTemplates(t *testing.T) {
+	r := &NotifyGroup{}
+	require.NoError(t, r.checkTemplates(map[string]string{"stdout": "full", "telegram": "short", "mattermost-bot": "short"}))
+
+	err := r.checkTemplates(map[string]string{"telegram": "short", "telgram": "short"})
+	assert.ErrorContains(t, err, `template is assigned to unknown destination "telgram", expected one of: stdout, stderr, telegram`)
+}
+
+func TestNotifyGroup_builders(t *testing.T) {
+	r := &NotifyGroup{Github: GithubNotifierGroup{Update: "replace"}}
+
+	idempotent := lo.FilterMap(r.builders(), func(b notifierBuilder, _ int) (string, bool) {
+		return b.name, b.idempotent
+	})
+	assert.Equal(t, []string{"github", "gitlab", "matrix"}, idempotent)
+}
+
+func TestWebhookGroup_HeadersFromEnv(t *testing.T) {
+	t.Setenv("WEBHOOK_HEADER", "Accept: application/json, text/plain\nAuthorization: Bearer token")
+
+	var opts struct {
+		Webhook WebhookGroup `group:"webhook" namespace:"webhook" env-namespace:"WEBHOOK"`
+	}
+	_, err := flags.NewParser(&opts, flags.Default).ParseArgs(nil)
+	require.NoError(t, err)
+
+	dest, err := opts.Webhook.build()
+	require.NoError(t, err)
+	require.IsType(t, &notify.Webhook{}, dest)
+
+	assert.Equal(t, map[string]string{
+		"Accept":        "application/json, text/plain",
+		"Authorization": "Bearer token",
+	}, dest.(*notify.Webhook).Headers)
+}
@@ -22,6 +22,8 @@ type Preview struct {
 	DataFile     string            `long:"data-file" env:"DATA_FILE" description:"path to the file with release data" required:"true"`
 	Extras       map[string]string `long:"extras" env:"EXTRAS" env-delim:"," description:"extra variables to use in the template, will be merged (env primary) with ones in the config file"`
 	ConfLocation string            `long:"conf-location" env:"CONF_LOCATION" description:"location to the config file" required:"true"`
+	Destination  string            `long:"destination" env:"DESTINATION" description:"name of the destination to preview release notes for, e.g. telegram"`
+	ClosingRefs  bool              `long:"closing-refs" env:"CLOSING_REFS" description:"attach pull requests to tickets referenced after closing keywords in their bodies, e.g. 'Closes #42'"`
 }
 
 // Execute prints the release notes to stdout.
@@ -49,6 +51,10 @@ func (p Preview) Execute(_ []string) error {
 		return fmt.Errorf("read release notes builder config: %w", err)
 	}
 
+	if err = (&NotifyGroup{}).checkTemplates(rnbCfg.Destinations); err != nil {
+		return fmt.Errorf("check release notes builder config: %w", err)
+	}
+
 	trackerMock := &tengine.Tracker{Interface: &tengine.InterfaceMock{
 		GetFunc: func(_ context.Context, id string) (task.Ticket, error) {
 			for _, t := range data.Tasks {
@@ -76,7 +82,7 @@ func (p Preview) Execute(_ []string) error {
 		Addon: eval.MultiAddon{
 			&eval.Git{Engine: gengine.Unsupported{}},
 			&eval.Task{Tracker: trackerMock},
-			&notes.EvalAddon{TaskTracker: trackerMock},
+			&notes.EvalAddon{TaskTracker: trackerMock, ClosingRefs: p.ClosingRefs},
 		},
 	}
 
@@ -85,7 +91,7 @@ func (p Preview) Execute(_ []string) error {
 		return fmt.Errorf("prepare release notes builder: %w", err)
 	}
 
-	rn, err := rnb.Build(context.Background(), notes.BuildRequest{
+	rn, err := rnb.BuildAll(context.Background(), notes.BuildRequest{
 		From:      data.From,
 		To:        data.To,
 		ClosedPRs: data.PullRequests,
@@ -100,7 +106,12 @@ func (p Preview) Execute(_ []string) error {
 		Name:   "stdout",
 	}
 
-	if err = wr.Send(context.Background(), rn); err != nil {
+	text := rn.Text
+	if t, ok := rn.Texts[p.Destination]; ok {
+		text = t
+	}
+
+	if err = wr.Send(context.Background(), text); err != nil {
 		return fmt.Errorf("print release notes: %w", err)
 	}
 
@@ -0,0 +1,431 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Semior001/releaseit/app/git"
+	"github.com/go-pkgz/requester"
+	"github.com/go-pkgz/requester/middleware"
+	"github.com/go-pkgz/requester/middleware/logger"
+	"github.com/samber/lo"
+)
+
+const (
+	azureAPIVersion = "7.1"
+	// azurePerPage is the page size to request from azure API.
+	azurePerPage = 100
+)
+
+// Azure implements Repository with Azure DevOps Repos API below it.
+type Azure struct {
+	cl         *http.Client
+	baseURL    string
+	org        string
+	project    string
+	repository string
+
+	webURL        string // url of the repository in browser
+	defaultBranch string
+}
+
+// AzureParams contains parameters for azure engine.
+type AzureParams struct {
+	BaseURL      string // https://dev.azure.com by default
+	Organization string
+	Project      string
+	Repository   string // name or ID of the repository
+	Token        string // personal access token
+	HTTPClient   http.Client
+}
+
+// NewAzure makes new instance of Azure.
+func NewAzure(ctx context.Context, params AzureParams) (*Azure, error) {
+	if params.BaseURL == "" {
+		params.BaseURL = "https://dev.azure.com"
+	}
+
+	cl := requester.New(params.HTTPClient, logger.New(logger.Func(log.Printf), logger.Prefix("[DEBUG]")).Middleware)
+
+	if params.Token != "" {
+		// azure expects personal access tokens in basic auth with empty username
+		cl.Use(middleware.BasicAuth("", params.Token))
+	}
+
+	svc := &Azure{
+		cl:         cl.Client(),
+		baseURL:    strings.TrimSuffix(params.BaseURL, "/"),
+		org:        params.Organization,
+		project:    params.Project,
+		repository: params.Repository,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultPingTimeout)
+	defer cancel()
+
+	var repo struct {
+		WebURL        string `json:"webUrl"`
+		DefaultBranch string `json:"defaultBranch"`
+	}
+
+	if _, err := svc.do(ctx, http.MethodGet, "", nil, nil, &repo); err != nil {
+		return nil, fmt.Errorf("check connection to azure: %w", err)
+	}
+
+	svc.webURL = repo.WebURL
+	svc.defaultBranch = strings.TrimPrefix(repo.DefaultBranch, "refs/heads/")
+
+	return svc, nil
+}
+
+// GetLastCommitOfBranch returns the SHA or alias of the last commit in the branch.
+func (a *Azure) GetLastCommitOfBranch(ctx context.Context, branchName string) (string, error) {
+	var stats struct {
+		Commit struct {
+			CommitID string `json:"commitId"`
+		} `json:"commit"`
+	}
+
+	if _, err := a.do(ctx, http.MethodGet, "/stats/branches", url.Values{"name": {branchName}}, nil, &stats); err != nil {
+		return "", fmt.Errorf("get branch stats: %w", err)
+	}
+
+	return stats.Commit.CommitID, nil
+}
+
+// Compare two commits by their SHA.
+func (a *Azure) Compare(ctx context.Context, fromSHA, toSHA string) (git.CommitsComparison, error) {
+	from, err := a.resolve(ctx, fromSHA)
+	if err != nil {
+		return git.CommitsComparison{}, fmt.Errorf("resolve %s: %w", fromSHA, err)
+	}
+
+	to, err := a.resolve(ctx, toSHA)
+	if err != nil {
+		return git.CommitsComparison{}, fmt.Errorf("resolve %s: %w", toSHA, err)
+	}
+
+	var commits []azureCommit
+	for {
+		q := url.Values{
+			"searchCriteria.itemVersion.version":        {to},
+			"searchCriteria.itemVersion.versionType":    {"commit"},
+			"searchCriteria.compareVersion.version":     {from},
+			"searchCriteria.compareVersion.versionType": {"commit"},
+			"searchCriteria.$top":                       {strconv.Itoa(azurePerPage)},
+			"searchCriteria.$skip":                      {strconv.Itoa(len(commits))},
+		}
+
+		var page struct {
+			Value []azureCommit `json:"value"`
+		}
+
+		if _, err = a.do(ctx, http.MethodGet, "/commits", q, nil, &page); err != nil {
+			return git.CommitsComparison{}, fmt.Errorf("azure returned error: %w", err)
+		}
+
+		// server might limit the page size to less than requested,
+		// so the end of the list is detected only by an empty page
+		if len(page.Value) == 0 {
+			break
+		}
+
+		commits = append(commits, page.Value...)
+	}
+
+	// azure returns commits in reverse chronological order
+	res := make([]git.Commit, len(commits))
+	for i, commit := range commits {
+		res[len(commits)-1-i] = a.transformCommit(commit)
+	}
+
+	return git.CommitsComparison{
+		Commits:      res,
+		TotalCommits: len(res),
+	}, nil
+}
+
+// ListPRsOfCommit returns pull requests, which were merged by the commit with the given SHA.
+func (a *Azure) ListPRsOfCommit(ctx context.Context, sha string) ([]git.PullRequest, error) {
+	req := map[string]any{
+		"queries": []map[string]any{{"type": "lastMergeCommit", "items": []string{sha}}},
+	}
+
+	var resp struct {
+		Results []map[string][]azurePR `json:"results"`
+	}
+
+	if _, err := a.do(ctx, http.MethodPost, "/pullrequestquery", nil, req, &resp); err != nil {
+		return nil, fmt.Errorf("query pull requests by merge commit: %w", err)
+	}
+
+	var res []git.PullRequest
+	for _, result := range resp.Results {
+		for _, pr := range result[sha] {
+			res = append(res, a.transformPR(pr))
+		}
+	}
+
+	return res, nil
+}
+
+// ListTags returns all tags of the repository in descending order of
+// their commits' dates, as azure doesn't provide creation dates of tags.
+func (a *Azure) ListTags(ctx context.Context) ([]git.Tag, error) {
+	refs, err := a.listRefs(ctx, "tags/")
+	if err != nil {
+		return nil, fmt.Errorf("list tag refs: %w", err)
+	}
+
+	commits := map[string]git.Commit{}
+	ids := lo.Uniq(lo.Map(refs, func(ref azureRef, _ int) string { return ref.commitID() }))
+	for _, chunk := range lo.Chunk(ids, azurePerPage) {
+		var batch struct {
+			Value []azureCommit `json:"value"`
+		}
+
+		if _, err = a.do(ctx, http.MethodPost, "/commitsbatch", nil, map[string]any{"ids": chunk}, &batch); err != nil {
+			return nil, fmt.Errorf("get commits of tags: %w", err)
+		}
+
+		for _, commit := range batch.Value {
+			commits[commit.CommitID] = a.transformCommit(commit)
+		}
+	}
+
+	res := make([]git.Tag, len(refs))
+	for i, ref := range refs {
+		commit, ok := commits[ref.commitID()]
+		if !ok {
+			commit = git.Commit{SHA: ref.commitID()}
+		}
+		res[i] = git.Tag{Name: strings.TrimPrefix(ref.Name, "refs/tags/"), Commit: commit}
+	}
+
+	sort.SliceStable(res, func(i, j int) bool { return res[i].Commit.CommittedAt.After(res[j].Commit.CommittedAt) })
+
+	return res, nil
+}
+
+func (a *Azure) listRefs(ctx context.Context, filter string) ([]azureRef, error) {
+	var res []azureRef
+
+	q := url.Values{"filter": {filter}, "peelTags": {"true"}, "$top": {strconv.Itoa(azurePerPage)}}
+	for {
+		var page struct {
+			Value []azureRef `json:"value"`
+		}
+
+		hdr, err := a.do(ctx, http.MethodGet, "/refs", q, nil, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, page.Value...)
+
+		token := hdr.Get("x-ms-continuationtoken")
+		if token == "" {
+			return res, nil
+		}
+		q.Set("continuationToken", token)
+	}
+}
+
+var azureSHARx = regexp.MustCompile(`^[0-9a-fA-F]{40}$`)
+
+// resolve returns the commit SHA by the given tag, branch or commit SHA, as
+// azure requires to specify the type of the version in commits queries.
+func (a *Azure) resolve(ctx context.Context, ref string) (string, error) {
+	if azureSHARx.MatchString(ref) {
+		return ref, nil
+	}
+
+	if ref == "HEAD" {
+		ref = a.defaultBranch
+	}
+
+	for _, filter := range []string{"tags/" + ref, "heads/" + ref} {
+		refs, err := a.listRefs(ctx, filter)
+		if err != nil {
+			return "", fmt.Errorf("list refs by %s: %w", filter, err)
+		}
+
+		// filter matches refs by prefix, so we need to find the exact one
+		for _, r := range refs {
+			if r.Name == "refs/"+filter {
+				return r.commitID(), nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("ref %s not found", ref)
+}
+
+// do makes a request to the repository API by the given path
+// and decodes the response into dst.
+func (a *Azure) do(ctx context.Context, method, path string, q url.Values, body, dst any) (http.Header, error) {
+	if q == nil {
+		q = url.Values{}
+	}
+	q.Set("api-version", azureAPIVersion)
+
+	u := fmt.Sprintf("%s/%s/%s/_apis/git/repositories/%s%s?%s", a.baseURL,
+		url.PathEscape(a.org), url.PathEscape(a.project), url.PathEscape(a.repository), path, q.Encode())
+
+	var rd io.Reader = http.NoBody
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("marshal body: %w", err)
+		}
+		rd = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, rd)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := a.cl.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer func() {
+		if err = resp.Body.Close(); err != nil {
+			log.Printf("[WARN] can't close response body, %s", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Message string `json:"message"`
+		}
+		if err = json.NewDecoder(resp.Body).Decode(&e); err == nil && e.Message != "" {
+			return nil, fmt.Errorf("unexpected status code %d, message: %q", resp.StatusCode, e.Message)
+		}
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(dst); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	return resp.Header, nil
+}
+
+type azureUser struct {
+	Name  string    `json:"name"`
+	Email string    `json:"email"`
+	Date  time.Time `json:"date"`
+}
+
+type azureIdentity struct {
+	DisplayName string `json:"displayName"`
+	UniqueName  string `json:"uniqueName"`
+}
+
+type azureRef struct {
+	Name           string `json:"name"`
+	ObjectID       string `json:"objectId"`
+	PeeledObjectID string `json:"peeledObjectId"`
+}
+
+// commitID returns the SHA of the commit, annotated tags point to the tag object,
+// so the peeled SHA is returned for them.
+func (r azureRef) commitID() string {
+	if r.PeeledObjectID != "" {
+		return r.PeeledObjectID
+	}
+	return r.ObjectID
+}
+
+type azureCommit struct {
+	CommitID  string    `json:"commitId"`
+	Comment   string    `json:"comment"`
+	Author    azureUser `json:"author"`
+	Committer azureUser `json:"committer"`
+	Parents   []string  `json:"parents"`
+	RemoteURL string    `json:"remoteUrl"`
+}
+
+type azurePR struct {
+	PullRequestID int           `json:"pullRequestId"`
+	Title         string        `json:"title"`
+	Description   string        `json:"description"`
+	Status        string        `json:"status"`
+	ClosedDate    time.Time     `json:"closedDate"`
+	CreatedBy     azureIdentity `json:"createdBy"`
+	SourceRefName string        `json:"sourceRefName"`
+	TargetRefName string        `json:"targetRefName"`
+	Labels        []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	Reviewers []azureIdentity `json:"reviewers"`
+}
+
+func (a *Azure) transformCommit(commit azureCommit) git.Commit {
+	return git.Commit{
+		SHA:         commit.CommitID,
+		ParentSHAs:  commit.Parents,
+		Message:     commit.Comment,
+		CommittedAt: commit.Committer.Date,
+		AuthoredAt:  commit.Author.Date,
+		URL:         commit.RemoteURL,
+		Author:      git.User{Username: commit.Author.Name, Email: commit.Author.Email},
+		Committer:   git.User{Username: commit.Committer.Name, Email: commit.Committer.Email},
+	}
+}
+
+func (a *Azure) transformPR(pr azurePR) git.PullRequest {
+	res := git.PullRequest{
+		Number:       pr.PullRequestID,
+		Title:        pr.Title,
+		Body:         pr.Description,
+		Author:       a.transformIdentity(pr.CreatedBy),
+		SourceBranch: strings.TrimPrefix(pr.SourceRefName, "refs/heads/"),
+		TargetBranch: strings.TrimPrefix(pr.TargetRefName, "refs/heads/"),
+	}
+
+	// abandoned pull requests have closed date too,
+	// so we take it only for completed ones.
+	if pr.Status == "completed" {
+		res.ClosedAt = pr.ClosedDate
+	}
+
+	if a.webURL != "" {
+		res.URL = fmt.Sprintf("%s/pullrequest/%d", a.webURL, pr.PullRequestID)
+	}
+
+	for _, label := range pr.Labels {
+		res.Labels = append(res.Labels, label.Name)
+	}
+
+	for _, reviewer := range pr.Reviewers {
+		res.Assignees = append(res.Assignees, a.transformIdentity(reviewer))
+	}
+
+	return res
+}
+
+func (a *Azure) transformIdentity(identity azureIdentity) git.User {
+	// unique name is an email for AAD users, and a DOMAIN\login for on-premise ones
+	if strings.Contains(identity.UniqueName, "@") {
+		return git.User{Username: identity.DisplayName, Email: identity.UniqueName}
+	}
+	return git.User{Username: identity.UniqueName}
+}
@@ -0,0 +1,255 @@
+package engine
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Semior001/releaseit/app/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const azureRepoPath = "/org/project/_apis/git/repositories/repo"
+
+func TestAzure_GetLastCommitOfBranch(t *testing.T) {
+	svc := newAzure(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, azureRepoPath+"/stats/branches", r.URL.Path)
+		assert.Equal(t, "branch", r.URL.Query().Get("name"))
+		_, err := w.Write([]byte(`{"name": "branch", "commit": {"commitId": "sha"}}`))
+		require.NoError(t, err)
+	})
+
+	sha, err := svc.GetLastCommitOfBranch(context.Background(), "branch")
+	require.NoError(t, err)
+	assert.Equal(t, "sha", sha)
+}
+
+func TestAzure_Compare(t *testing.T) {
+	const (
+		fromSHA = "0000000000000000000000000000000000000001"
+		toSHA   = "0000000000000000000000000000000000000002"
+	)
+
+	svc := newAzure(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case azureRepoPath + "/refs":
+			assert.Equal(t, "true", r.URL.Query().Get("peelTags"))
+			switch r.URL.Query().Get("filter") {
+			case "tags/v0.1.0":
+				_, err := w.Write([]byte(`{"value": [
+					{"name": "refs/tags/v0.1.0-rc", "objectId": "rc"},
+					{"name": "refs/tags/v0.1.0", "objectId": "tag-object", "peeledObjectId": "` + fromSHA + `"}
+				]}`))
+				require.NoError(t, err)
+			case "tags/master", "tags/unknown", "heads/unknown":
+				_, err := w.Write([]byte(`{"value": []}`))
+				require.NoError(t, err)
+			case "heads/master":
+				_, err := w.Write([]byte(`{"value": [{"name": "refs/heads/master", "objectId": "` + toSHA + `"}]}`))
+				require.NoError(t, err)
+			default:
+				t.Fatalf("unexpected filter %s", r.URL.Query().Get("filter"))
+			}
+		case azureRepoPath + "/commits":
+			q := r.URL.Query()
+			assert.Equal(t, toSHA, q.Get("searchCriteria.itemVersion.version"))
+			assert.Equal(t, "commit", q.Get("searchCriteria.itemVersion.versionType"))
+			assert.Equal(t, fromSHA, q.Get("searchCriteria.compareVersion.version"))
+			assert.Equal(t, "commit", q.Get("searchCriteria.compareVersion.versionType"))
+
+			// server limits the page size to less than requested
+			switch q.Get("searchCriteria.$skip") {
+			case "0":
+				_, err := w.Write([]byte(`{"count": 1, "value": [
+					{
+						"commitId": "sha2",
+						"comment": "message2",
+						"parents": ["sha", "parent2"]
+					}
+				]}`))
+				require.NoError(t, err)
+			case "1":
+				_, err := w.Write([]byte(`{"count": 1, "value": [
+					{
+						"commitId": "sha",
+						"comment": "message",
+						"author": {"name": "author", "email": "author@example.com", "date": "2020-01-01T00:00:00Z"},
+						"committer": {"name": "committer", "email": "committer@example.com", "date": "2020-01-02T00:00:00Z"},
+						"remoteUrl": "url"
+					}
+				]}`))
+				require.NoError(t, err)
+			case "2":
+				_, err := w.Write([]byte(`{"count": 0, "value": []}`))
+				require.NoError(t, err)
+			default:
+				t.Fatalf("unexpected skip %s", q.Get("searchCriteria.$skip"))
+			}
+		default:
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	comp, err := svc.Compare(context.Background(), "v0.1.0", "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, git.CommitsComparison{
+		Commits: []git.Commit{
+			{
+				SHA:         "sha",
+				Message:     "message",
+				CommittedAt: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+				AuthoredAt:  time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+				URL:         "url",
+				Author:      git.User{Username: "author", Email: "author@example.com"},
+				Committer:   git.User{Username: "committer", Email: "committer@example.com"},
+			},
+			{SHA: "sha2", ParentSHAs: []string{"sha", "parent2"}, Message: "message2"},
+		},
+		TotalCommits: 2,
+	}, comp)
+
+	_, err = svc.Compare(context.Background(), "unknown", toSHA)
+	assert.ErrorContains(t, err, "resolve unknown")
+}
+
+func TestAzure_ListPRsOfCommit(t *testing.T) {
+	svc := newAzure(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, azureRepoPath+"/pullrequestquery", r.URL.Path)
+		require.Equal(t, http.MethodPost, r.Method)
+
+		var req struct {
+			Queries []struct {
+				Type  string   `json:"type"`
+				Items []string `json:"items"`
+			} `json:"queries"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Len(t, req.Queries, 1)
+		assert.Equal(t, "lastMergeCommit", req.Queries[0].Type)
+		assert.Equal(t, []string{"sha"}, req.Queries[0].Items)
+
+		_, err := w.Write([]byte(`{"results": [{"sha": [{
+			"pullRequestId": 1,
+			"title": "title",
+			"description": "description",
+			"status": "completed",
+			"closedDate": "2020-01-01T00:00:00Z",
+			"createdBy": {"displayName": "Author", "uniqueName": "author@example.com"},
+			"sourceRefName": "refs/heads/feature",
+			"targetRefName": "refs/heads/master",
+			"labels": [{"name": "label1"}, {"name": "label2"}],
+			"reviewers": [{"displayName": "Reviewer", "uniqueName": "DOMAIN\\reviewer"}]
+		}]}]}`))
+		require.NoError(t, err)
+	})
+
+	prs, err := svc.ListPRsOfCommit(context.Background(), "sha")
+	require.NoError(t, err)
+	assert.Equal(t, []git.PullRequest{{
+		Number:       1,
+		Title:        "title",
+		Body:         "description",
+		Author:       git.User{Username: "Author", Email: "author@example.com"},
+		Labels:       []string{"label1", "label2"},
+		ClosedAt:     time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		SourceBranch: "feature",
+		TargetBranch: "master",
+		URL:          "https://dev.azure.com/org/project/_git/repo/pullrequest/1",
+		Assignees:    []git.User{{Username: "DOMAIN\\reviewer"}},
+	}}, prs)
+}
+
+func TestAzure_ListTags(t *testing.T) {
+	svc := newAzure(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case azureRepoPath + "/refs":
+			assert.Equal(t, "tags/", r.URL.Query().Get("filter"))
+			switch r.URL.Query().Get("continuationToken") {
+			case "":
+				w.Header().Set("x-ms-continuationtoken", "next")
+				_, err := w.Write([]byte(`{"value": [{"name": "refs/tags/v0.1.0", "objectId": "tag-object", "peeledObjectId": "sha1"}]}`))
+				require.NoError(t, err)
+			case "next":
+				_, err := w.Write([]byte(`{"value": [{"name": "refs/tags/v0.2.0", "objectId": "sha2"}]}`))
+				require.NoError(t, err)
+			default:
+				t.Fatalf("unexpected continuation token %s", r.URL.Query().Get("continuationToken"))
+			}
+		case azureRepoPath + "/commitsbatch":
+			require.Equal(t, http.MethodPost, r.Method)
+
+			var req struct {
+				IDs []string `json:"ids"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, []string{"sha1", "sha2"}, req.IDs)
+
+			_, err := w.Write([]byte(`{"value": [
+				{"commitId": "sha1", "committer": {"date": "2020-01-01T00:00:00Z"}},
+				{"commitId": "sha2", "committer": {"date": "2020-01-02T00:00:00Z"}}
+			]}`))
+			require.NoError(t, err)
+		default:
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	tags, err := svc.ListTags(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []git.Tag{
+		{Name: "v0.2.0", Commit: git.Commit{SHA: "sha2", CommittedAt: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)}},
+		{Name: "v0.1.0", Commit: git.Commit{SHA: "sha1", CommittedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}},
+	}, tags)
+}
+
+func TestAzure_Error(t *testing.T) {
+	svc := newAzure(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, err := w.Write([]byte(`{"message": "TF401175: The version descriptor could not be resolved"}`))
+		require.NoError(t, err)
+	})
+
+	_, err := svc.GetLastCommitOfBranch(context.Background(), "branch")
+	assert.EqualError(t, err, `get branch stats: unexpected status code 404, `+
+		`message: "TF401175: The version descriptor could not be resolved"`)
+}
+
+func newAzure(t *testing.T, h http.HandlerFunc) *Azure {
+	t.Helper()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Basic "+base64.StdEncoding.EncodeToString([]byte(":token")),
+			r.Header.Get("Authorization"), "token is not set")
+		require.Equal(t, azureAPIVersion, r.URL.Query().Get("api-version"))
+
+		if r.URL.Path == azureRepoPath {
+			_, err := w.Write([]byte(`{
+				"name": "repo",
+				"defaultBranch": "refs/heads/master",
+				"webUrl": "https://dev.azure.com/org/project/_git/repo"
+			}`))
+			require.NoError(t, err)
+			return
+		}
+
+		h(w, r)
+	}))
+	t.Cleanup(ts.Close)
+
+	svc, err := NewAzure(context.Background(), AzureParams{
+		BaseURL:      ts.URL + "/",
+		Organization: "org",
+		Project:      "project",
+		Repository:   "repo",
+		Token:        "token",
+		HTTPClient:   http.Client{Timeout: 5 * time.Second},
+	})
+	require.NoError(t, err)
+
+	return svc
+}
@@ -0,0 +1,295 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Semior001/releaseit/app/git"
+	"github.com/go-pkgz/requester"
+	"github.com/go-pkgz/requester/middleware"
+	"github.com/go-pkgz/requester/middleware/logger"
+	"github.com/samber/lo"
+)
+
+// bitbucketPerPage is the page size to request from bitbucket API.
+const bitbucketPerPage = 100
+
+// Bitbucket implements Repository with Bitbucket Server (Data Center) API below it.
+type Bitbucket struct {
+	cl         *http.Client
+	baseURL    string
+	projectKey string
+	repoSlug   string
+}
+
+// BitbucketParams contains parameters for bitbucket engine.
+type BitbucketParams struct {
+	BaseURL    string
+	Token      string
+	ProjectKey string
+	RepoSlug   string
+	HTTPClient http.Client
+}
+
+// NewBitbucket makes new instance of Bitbucket.
+func NewBitbucket(ctx context.Context, params BitbucketParams) (*Bitbucket, error) {
+	cl := requester.New(params.HTTPClient, logger.New(logger.Func(log.Printf), logger.Prefix("[DEBUG]")).Middleware)
+
+	if params.Token != "" {
+		cl.Use(middleware.Header("Authorization", "Bearer "+params.Token))
+	}
+
+	svc := &Bitbucket{
+		cl:         cl.Client(),
+		baseURL:    strings.TrimSuffix(params.BaseURL, "/"),
+		projectKey: params.ProjectKey,
+		repoSlug:   params.RepoSlug,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultPingTimeout)
+	defer cancel()
+
+	if err := svc.get(ctx, "", nil, &struct{}{}); err != nil {
+		return nil, fmt.Errorf("check connection to bitbucket: %w", err)
+	}
+
+	return svc, nil
+}
+
+// GetLastCommitOfBranch returns the SHA or alias of the last commit in the branch.
+func (b *Bitbucket) GetLastCommitOfBranch(ctx context.Context, branchName string) (string, error) {
+	branches, err := listBitbucket[bitbucketRef](ctx, b, "/branches", url.Values{"filterText": {branchName}})
+	if err != nil {
+		return "", fmt.Errorf("list branches: %w", err)
+	}
+
+	// filter matches branches by substring, so we need to find the exact one
+	for _, branch := range branches {
+		if branch.DisplayID == branchName {
+			return branch.LatestCommit, nil
+		}
+	}
+
+	return "", fmt.Errorf("branch %s not found", branchName)
+}
+
+// Compare two commits by their SHA.
+func (b *Bitbucket) Compare(ctx context.Context, fromSHA, toSHA string) (git.CommitsComparison, error) {
+	commits, err := listBitbucket[bitbucketCommit](ctx, b, "/commits", url.Values{"since": {fromSHA}, "until": {toSHA}})
+	if err != nil {
+		return git.CommitsComparison{}, fmt.Errorf("bitbucket returned error: %w", err)
+	}
+
+	// bitbucket returns commits in reverse chronological order
+	res := make([]git.Commit, len(commits))
+	for i, commit := range commits {
+		res[len(commits)-1-i] = b.transformCommit(commit)
+	}
+
+	return git.CommitsComparison{
+		Commits:      res,
+		TotalCommits: len(res),
+	}, nil
+}
+
+// ListPRsOfCommit returns pull requests associated with commit by the given SHA.
+func (b *Bitbucket) ListPRsOfCommit(ctx context.Context, sha string) ([]git.PullRequest, error) {
+	prs, err := listBitbucket[bitbucketPR](ctx, b, "/commits/"+url.PathEscape(sha)+"/pull-requests", nil)
+	if err != nil {
+		return nil, fmt.Errorf("list pull requests of commit: %w", err)
+	}
+
+	return lo.Map(prs, func(pr bitbucketPR, _ int) git.PullRequest { return b.transformPR(pr) }), nil
+}
+
+// ListTags returns all tags of the repository, most recently modified first.
+func (b *Bitbucket) ListTags(ctx context.Context) ([]git.Tag, error) {
+	tags, err := listBitbucket[bitbucketRef](ctx, b, "/tags", url.Values{"orderBy": {"MODIFICATION"}})
+	if err != nil {
+		return nil, fmt.Errorf("bitbucket returned error: %w", err)
+	}
+
+	return lo.Map(tags, func(tag bitbucketRef, _ int) git.Tag {
+		return git.Tag{Name: tag.DisplayID, Commit: git.Commit{SHA: tag.LatestCommit}}
+	}), nil
+}
+
+// listBitbucket walks all pages of the paged bitbucket API by the given path.
+func listBitbucket[T any](ctx context.Context, b *Bitbucket, path string, q url.Values) ([]T, error) {
+	if q == nil {
+		q = url.Values{}
+	}
+	q.Set("limit", strconv.Itoa(bitbucketPerPage))
+
+	var res []T
+	for start := 0; ; {
+		q.Set("start", strconv.Itoa(start))
+
+		var page struct {
+			Values        []T  `json:"values"`
+			IsLastPage    bool `json:"isLastPage"`
+			NextPageStart int  `json:"nextPageStart"`
+		}
+
+		if err := b.get(ctx, path, q, &page); err != nil {
+			return nil, err
+		}
+
+		res = append(res, page.Values...)
+
+		if page.IsLastPage || len(page.Values) == 0 {
+			return res, nil
+		}
+		start = page.NextPageStart
+	}
+}
+
+// get makes a GET request to the repository API by the given path
+// and decodes the response into dst.
+func (b *Bitbucket) get(ctx context.Context, path string, q url.Values, dst any) error {
+	u := fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s%s",
+		b.baseURL, url.PathEscape(b.projectKey), url.PathEscape(b.repoSlug), path)
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, http.NoBody)
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+
+	resp, err := b.cl.Do(req)
+	if err != nil {
+		return fmt.Errorf("do request: %w", err)
+	}
+	defer func() {
+		if err = resp.Body.Close(); err != nil {
+			log.Printf("[WARN] can't close response body, %s", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+		if err = json.NewDecoder(resp.Body).Decode(&e); err == nil && len(e.Errors) > 0 {
+			return fmt.Errorf("unexpected status code %d, message: %q", resp.StatusCode, e.Errors[0].Message)
+		}
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(dst); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+
+	return nil
+}
+
+type bitbucketUser struct {
+	Name         string `json:"name"`
+	EmailAddress string `json:"emailAddress"`
+}
+
+type bitbucketRef struct {
+	DisplayID    string `json:"displayId"`
+	LatestCommit string `json:"latestCommit"`
+}
+
+type bitbucketCommit struct {
+	ID                 string        `json:"id"`
+	Message            string        `json:"message"`
+	Author             bitbucketUser `json:"author"`
+	AuthorTimestamp    int64         `json:"authorTimestamp"`
+	Committer          bitbucketUser `json:"committer"`
+	CommitterTimestamp int64         `json:"committerTimestamp"`
+	Parents            []struct {
+		ID string `json:"id"`
+	} `json:"parents"`
+}
+
+type bitbucketPR struct {
+	ID          int    `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	State       string `json:"state"`
+	ClosedDate  int64  `json:"closedDate"`
+	Author      struct {
+		User bitbucketUser `json:"user"`
+	} `json:"author"`
+	Reviewers []struct {
+		User bitbucketUser `json:"user"`
+	} `json:"reviewers"`
+	FromRef bitbucketRef `json:"fromRef"`
+	ToRef   bitbucketRef `json:"toRef"`
+	Links   struct {
+		Self []struct {
+			Href string `json:"href"`
+		} `json:"self"`
+	} `json:"links"`
+}
+
+func (b *Bitbucket) transformCommit(commit bitbucketCommit) git.Commit {
+	res := git.Commit{
+		SHA:         commit.ID,
+		Message:     commit.Message,
+		CommittedAt: bitbucketTime(commit.CommitterTimestamp),
+		AuthoredAt:  bitbucketTime(commit.AuthorTimestamp),
+		URL:         fmt.Sprintf("%s/projects/%s/repos/%s/commits/%s", b.baseURL, b.projectKey, b.repoSlug, commit.ID),
+		Author:      b.transformUser(commit.Author),
+		Committer:   b.transformUser(commit.Committer),
+	}
+
+	for _, parent := range commit.Parents {
+		res.ParentSHAs = append(res.ParentSHAs, parent.ID)
+	}
+
+	return res
+}
+
+func (b *Bitbucket) transformPR(pr bitbucketPR) git.PullRequest {
+	res := git.PullRequest{
+		Number:       pr.ID,
+		Title:        pr.Title,
+		Body:         pr.Description,
+		Author:       b.transformUser(pr.Author.User),
+		SourceBranch: pr.FromRef.DisplayID,
+		TargetBranch: pr.ToRef.DisplayID,
+	}
+
+	// declined pull requests have closed date too,
+	// so we take it only for merged ones.
+	if pr.State == "MERGED" {
+		res.ClosedAt = bitbucketTime(pr.ClosedDate)
+	}
+
+	if len(pr.Links.Self) > 0 {
+		res.URL = pr.Links.Self[0].Href
+	}
+
+	for _, reviewer := range pr.Reviewers {
+		res.Assignees = append(res.Assignees, b.transformUser(reviewer.User))
+	}
+
+	return res
+}
+
+func (b *Bitbucket) transformUser(u bitbucketUser) git.User {
+	return git.User{Username: u.Name, Email: u.EmailAddress}
+}
+
+// bitbucketTime converts unix milliseconds timestamp to time.
+func bitbucketTime(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms).UTC()
+}
@@ -0,0 +1,185 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Semior001/releaseit/app/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBitbucket_GetLastCommitOfBranch(t *testing.T) {
+	svc := newBitbucket(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/rest/api/1.0/projects/PRJ/repos/repo/branches", r.URL.Path)
+		assert.Contains(t, []string{"master", "develop"}, r.URL.Query().Get("filterText"))
+		_, err := w.Write([]byte(`{"isLastPage": true, "values": [
+			{"displayId": "master-old", "latestCommit": "old"},
+			{"displayId": "master", "latestCommit": "sha"}
+		]}`))
+		require.NoError(t, err)
+	})
+
+	sha, err := svc.GetLastCommitOfBranch(context.Background(), "master")
+	require.NoError(t, err)
+	assert.Equal(t, "sha", sha)
+
+	_, err = svc.GetLastCommitOfBranch(context.Background(), "develop")
+	assert.EqualError(t, err, "branch develop not found")
+}
+
+func TestBitbucket_Compare(t *testing.T) {
+	svc := newBitbucket(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/rest/api/1.0/projects/PRJ/repos/repo/commits", r.URL.Path)
+		assert.Equal(t, "old", r.URL.Query().Get("since"))
+		assert.Equal(t, "new", r.URL.Query().Get("until"))
+
+		switch r.URL.Query().Get("start") {
+		case "0":
+			_, err := w.Write([]byte(`{"isLastPage": false, "nextPageStart": 1, "values": [{
+				"id": "sha2",
+				"message": "message2",
+				"author": {"name": "author", "emailAddress": "author@example.com"},
+				"authorTimestamp": 1577836800000,
+				"committer": {"name": "committer", "emailAddress": "committer@example.com"},
+				"committerTimestamp": 1577923200000,
+				"parents": [{"id": "sha"}, {"id": "parent2"}]
+			}]}`))
+			require.NoError(t, err)
+		case "1":
+			_, err := w.Write([]byte(`{"isLastPage": true, "values": [{"id": "sha", "message": "message", "parents": [{"id": "parent"}]}]}`))
+			require.NoError(t, err)
+		default:
+			t.Fatalf("unexpected start %s", r.URL.Query().Get("start"))
+		}
+	})
+
+	comp, err := svc.Compare(context.Background(), "old", "new")
+	require.NoError(t, err)
+	assert.Equal(t, git.CommitsComparison{
+		Commits: []git.Commit{
+			{
+				SHA:        "sha",
+				ParentSHAs: []string{"parent"},
+				Message:    "message",
+				URL:        svc.baseURL + "/projects/PRJ/repos/repo/commits/sha",
+			},
+			{
+				SHA:         "sha2",
+				ParentSHAs:  []string{"sha", "parent2"},
+				Message:     "message2",
+				CommittedAt: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+				AuthoredAt:  time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+				URL:         svc.baseURL + "/projects/PRJ/repos/repo/commits/sha2",
+				Author:      git.User{Username: "author", Email: "author@example.com"},
+				Committer:   git.User{Username: "committer", Email: "committer@example.com"},
+			},
+		},
+		TotalCommits: 2,
+	}, comp)
+}
+
+func TestBitbucket_ListPRsOfCommit(t *testing.T) {
+	svc := newBitbucket(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/rest/api/1.0/projects/PRJ/repos/repo/commits/sha/pull-requests", r.URL.Path)
+		_, err := w.Write([]byte(`{"isLastPage": true, "values": [
+			{
+				"id": 1,
+				"title": "title",
+				"description": "description",
+				"state": "MERGED",
+				"closedDate": 1577836800000,
+				"author": {"user": {"name": "author", "emailAddress": "author@example.com"}},
+				"reviewers": [{"user": {"name": "reviewer", "emailAddress": "reviewer@example.com"}}],
+				"fromRef": {"displayId": "feature"},
+				"toRef": {"displayId": "master"},
+				"links": {"self": [{"href": "url"}]}
+			},
+			{
+				"id": 2,
+				"title": "declined",
+				"state": "DECLINED",
+				"closedDate": 1577836800000
+			}
+		]}`))
+		require.NoError(t, err)
+	})
+
+	prs, err := svc.ListPRsOfCommit(context.Background(), "sha")
+	require.NoError(t, err)
+	assert.Equal(t, []git.PullRequest{
+		{
+			Number:       1,
+			Title:        "title",
+			Body:         "description",
+			Author:       git.User{Username: "author", Email: "author@example.com"},
+			ClosedAt:     time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			SourceBranch: "feature",
+			TargetBranch: "master",
+			URL:          "url",
+			Assignees:    []git.User{{Username: "reviewer", Email: "reviewer@example.com"}},
+		},
+		{Number: 2, Title: "declined"},
+	}, prs)
+}
+
+func TestBitbucket_ListTags(t *testing.T) {
+	svc := newBitbucket(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/rest/api/1.0/projects/PRJ/repos/repo/tags", r.URL.Path)
+		assert.Equal(t, "MODIFICATION", r.URL.Query().Get("orderBy"))
+		_, err := w.Write([]byte(`{"isLastPage": true, "values": [
+			{"displayId": "v0.2.0", "latestCommit": "sha2"},
+			{"displayId": "v0.1.0", "latestCommit": "sha1"}
+		]}`))
+		require.NoError(t, err)
+	})
+
+	tags, err := svc.ListTags(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []git.Tag{
+		{Name: "v0.2.0", Commit: git.Commit{SHA: "sha2"}},
+		{Name: "v0.1.0", Commit: git.Commit{SHA: "sha1"}},
+	}, tags)
+}
+
+func TestBitbucket_Error(t *testing.T) {
+	svc := newBitbucket(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, err := w.Write([]byte(`{"errors": [{"message": "Commit 'sha' does not exist"}]}`))
+		require.NoError(t, err)
+	})
+
+	_, err := svc.ListPRsOfCommit(context.Background(), "sha")
+	assert.EqualError(t, err, `list pull requests of commit: unexpected status code 404, message: "Commit 'sha' does not exist"`)
+}
+
+func newBitbucket(t *testing.T, h http.HandlerFunc) *Bitbucket {
+	t.Helper()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"), "token is not set")
+
+		if r.URL.Path == "/rest/api/1.0/projects/PRJ/repos/repo" {
+			_, err := w.Write([]byte(`{"slug": "repo"}`))
+			require.NoError(t, err)
+			return
+		}
+
+		h(w, r)
+	}))
+	t.Cleanup(ts.Close)
+
+	svc, err := NewBitbucket(context.Background(), BitbucketParams{
+		BaseURL:    ts.URL,
+		Token:      "token",
+		ProjectKey: "PRJ",
+		RepoSlug:   "repo",
+		HTTPClient: http.Client{Timeout: 5 * time.Second},
+	})
+	require.NoError(t, err)
+
+	return svc
+}
@@ -0,0 +1,360 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Semior001/releaseit/app/git"
+	"github.com/go-pkgz/requester"
+	"github.com/go-pkgz/requester/middleware"
+	"github.com/go-pkgz/requester/middleware/logger"
+	"github.com/samber/lo"
+)
+
+const (
+	// giteaPerPage is the default maximum page size allowed by gitea API.
+	giteaPerPage = 50
+	// giteaMaxWalkPages is the maximum number of pages of commits to list,
+	// if gitea doesn't return all commits in comparison.
+	giteaMaxWalkPages = 100
+)
+
+// Gitea implements Repository with gitea (or forgejo) API below it.
+type Gitea struct {
+	cl      *http.Client
+	baseURL string
+	owner   string
+	name    string
+}
+
+// GiteaParams contains parameters for gitea engine.
+type GiteaParams struct {
+	BaseURL    string
+	Token      string
+	Owner      string
+	Name       string
+	HTTPClient http.Client
+}
+
+// NewGitea makes new instance of Gitea.
+func NewGitea(ctx context.Context, params GiteaParams) (*Gitea, error) {
+	cl := requester.New(params.HTTPClient, logger.New(logger.Func(log.Printf), logger.Prefix("[DEBUG]")).Middleware)
+
+	if params.Token != "" {
+		cl.Use(middleware.Header("Authorization", "token "+params.Token))
+	}
+
+	svc := &Gitea{
+		cl:      cl.Client(),
+		baseURL: strings.TrimSuffix(params.BaseURL, "/"),
+		owner:   params.Owner,
+		name:    params.Name,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultPingTimeout)
+	defer cancel()
+
+	if err := svc.get(ctx, "", nil, &struct{}{}); err != nil {
+		return nil, fmt.Errorf("check connection to gitea: %w", err)
+	}
+
+	return svc, nil
+}
+
+// GetLastCommitOfBranch returns the SHA or alias of the last commit in the branch.
+func (g *Gitea) GetLastCommitOfBranch(ctx context.Context, branchName string) (string, error) {
+	var branch struct {
+		Commit struct {
+			ID string `json:"id"`
+		} `json:"commit"`
+	}
+
+	if err := g.get(ctx, "/branches/"+url.PathEscape(branchName), nil, &branch); err != nil {
+		return "", fmt.Errorf("get branch: %w", err)
+	}
+
+	return branch.Commit.ID, nil
+}
+
+// Compare two commits by their SHA.
+// Gitea limits the number of commits in comparison, so if it doesn't
+// return all of them, commits are listed from the head commit down to the base.
+func (g *Gitea) Compare(ctx context.Context, fromSHA, toSHA string) (git.CommitsComparison, error) {
+	var comp struct {
+		TotalCommits int           `json:"total_commits"`
+		Commits      []giteaCommit `json:"commits"`
+	}
+
+	if err := g.get(ctx, fmt.Sprintf("/compare/%s...%s", url.PathEscape(fromSHA), url.PathEscape(toSHA)), nil, &comp); err != nil {
+		return git.CommitsComparison{}, fmt.Errorf("gitea returned error: %w", err)
+	}
+
+	if len(comp.Commits) < comp.TotalCommits {
+		log.Printf("[DEBUG] gitea returned %d out of %d commits in comparison between %s and %s, "+
+			"listing commits down to %s", len(comp.Commits), comp.TotalCommits, fromSHA, toSHA, fromSHA)
+
+		var err error
+		if comp.Commits, err = g.commitsBetween(ctx, fromSHA, toSHA, comp.TotalCommits); err != nil {
+			return git.CommitsComparison{}, fmt.Errorf("list commits between %s and %s: %w", fromSHA, toSHA, err)
+		}
+	}
+
+	return git.CommitsComparison{
+		Commits:      lo.Map(comp.Commits, func(c giteaCommit, _ int) git.Commit { return g.transformCommit(c) }),
+		TotalCommits: comp.TotalCommits,
+	}, nil
+}
+
+// commitsBetween lists commits reachable from the head commit, but not
+// from the base one, in the same way as Github.commitsBetween does.
+// Commits, reachable from the base one, are also asked to be excluded
+// by the server, but older versions of gitea ignore this parameter.
+func (g *Gitea) commitsBetween(ctx context.Context, baseSHA, headSHA string, expected int) ([]giteaCommit, error) {
+	var (
+		listed  []giteaCommit
+		parents = map[string][]string{}
+		tipSHA  string // the resolved head, as it might be an alias
+	)
+
+	for page := 1; ; page++ {
+		if page > giteaMaxWalkPages {
+			return nil, fmt.Errorf("expected %d commits are not found in the last %d commits of %s",
+				expected, giteaMaxWalkPages*giteaPerPage, headSHA)
+		}
+
+		var commits []giteaCommit
+
+		q := url.Values{
+			"sha":          {headSHA},
+			"not":          {baseSHA},
+			"stat":         {"false"},
+			"verification": {"false"},
+			"files":        {"false"},
+			"page":         {strconv.Itoa(page)},
+			"limit":        {strconv.Itoa(giteaPerPage)},
+		}
+		if err := g.get(ctx, "/commits", q, &commits); err != nil {
+			return nil, fmt.Errorf("list commits: %w", err)
+		}
+
+		for _, commit := range commits {
+			if tipSHA == "" {
+				tipSHA = commit.SHA
+			}
+
+			listed = append(listed, commit)
+			parents[commit.SHA] = []string{}
+			for _, parent := range commit.Parents {
+				parents[commit.SHA] = append(parents[commit.SHA], parent.SHA)
+			}
+		}
+
+		wanted, complete := walkCommitsBetween(parents, baseSHA, tipSHA)
+		if len(commits) == 0 || complete && len(wanted) == expected {
+			return lo.Filter(listed, func(c giteaCommit, _ int) bool { return wanted[c.SHA] }), nil
+		}
+	}
+}
+
+// ListPRsOfCommit returns pull requests associated with commit by the given SHA.
+func (g *Gitea) ListPRsOfCommit(ctx context.Context, sha string) ([]git.PullRequest, error) {
+	var pr giteaPR
+
+	if err := g.get(ctx, "/commits/"+url.PathEscape(sha)+"/pull", nil, &pr); err != nil {
+		// gitea responds with 404, if commit doesn't belong to any pull request
+		if errors.Is(err, errGiteaNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get pull request of commit: %w", err)
+	}
+
+	return []git.PullRequest{g.transformPR(pr)}, nil
+}
+
+// ListTags returns all tags of the repository.
+func (g *Gitea) ListTags(ctx context.Context) ([]git.Tag, error) {
+	var res []git.Tag
+
+	for page := 1; ; page++ {
+		var tags []struct {
+			Name   string `json:"name"`
+			Commit struct {
+				SHA     string    `json:"sha"`
+				URL     string    `json:"url"`
+				Created time.Time `json:"created"`
+			} `json:"commit"`
+		}
+
+		q := url.Values{"page": {strconv.Itoa(page)}, "limit": {strconv.Itoa(giteaPerPage)}}
+		if err := g.get(ctx, "/tags", q, &tags); err != nil {
+			return nil, fmt.Errorf("gitea returned error: %w", err)
+		}
+
+		for _, tag := range tags {
+			res = append(res, git.Tag{
+				Name: tag.Name,
+				Commit: git.Commit{
+					SHA:         tag.Commit.SHA,
+					URL:         tag.Commit.URL,
+					CommittedAt: tag.Commit.Created,
+				},
+			})
+		}
+
+		// server might limit the page size to less than requested,
+		// so the end of the list is detected only by an empty page
+		if len(tags) == 0 {
+			return res, nil
+		}
+	}
+}
+
+var errGiteaNotFound = errors.New("not found")
+
+// get makes a GET request to the repository API by the given path
+// and decodes the response into dst.
+func (g *Gitea) get(ctx context.Context, path string, q url.Values, dst any) error {
+	u := fmt.Sprintf("%s/api/v1/repos/%s/%s%s", g.baseURL, url.PathEscape(g.owner), url.PathEscape(g.name), path)
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, http.NoBody)
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+
+	resp, err := g.cl.Do(req)
+	if err != nil {
+		return fmt.Errorf("do request: %w", err)
+	}
+	defer func() {
+		if err = resp.Body.Close(); err != nil {
+			log.Printf("[WARN] can't close response body, %s", err)
+		}
+	}()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return errGiteaNotFound
+	case resp.StatusCode != http.StatusOK:
+		var e struct {
+			Message string `json:"message"`
+		}
+		if err = json.NewDecoder(resp.Body).Decode(&e); err == nil && e.Message != "" {
+			return fmt.Errorf("unexpected status code %d, message: %q", resp.StatusCode, e.Message)
+		}
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(dst); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+
+	return nil
+}
+
+type giteaUser struct {
+	Login string `json:"login"`
+	Email string `json:"email"`
+}
+
+type giteaCommit struct {
+	SHA     string `json:"sha"`
+	HTMLURL string `json:"html_url"`
+	Commit  struct {
+		Message string `json:"message"`
+		Author  struct {
+			Name  string    `json:"name"`
+			Email string    `json:"email"`
+			Date  time.Time `json:"date"`
+		} `json:"author"`
+		Committer struct {
+			Name  string    `json:"name"`
+			Email string    `json:"email"`
+			Date  time.Time `json:"date"`
+		} `json:"committer"`
+	} `json:"commit"`
+	Author    *giteaUser `json:"author"`
+	Committer *giteaUser `json:"committer"`
+	Parents   []struct {
+		SHA string `json:"sha"`
+	} `json:"parents"`
+}
+
+type giteaLabel struct {
+	Name string `json:"name"`
+}
+
+type giteaPR struct {
+	Number   int          `json:"number"`
+	Title    string       `json:"title"`
+	Body     string       `json:"body"`
+	User     giteaUser    `json:"user"`
+	Labels   []giteaLabel `json:"labels"`
+	MergedAt *time.Time   `json:"merged_at"`
+	Head     struct {
+		Ref string `json:"ref"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+	HTMLURL   string      `json:"html_url"`
+	Assignees []giteaUser `json:"assignees"`
+}
+
+func (g *Gitea) transformCommit(cmt giteaCommit) git.Commit {
+	res := git.Commit{
+		SHA:         cmt.SHA,
+		Message:     cmt.Commit.Message,
+		CommittedAt: cmt.Commit.Committer.Date,
+		AuthoredAt:  cmt.Commit.Author.Date,
+		URL:         cmt.HTMLURL,
+		Author:      git.User{Username: cmt.Commit.Author.Name, Email: cmt.Commit.Author.Email},
+		Committer:   git.User{Username: cmt.Commit.Committer.Name, Email: cmt.Commit.Committer.Email},
+	}
+
+	for _, parent := range cmt.Parents {
+		res.ParentSHAs = append(res.ParentSHAs, parent.SHA)
+	}
+
+	// prefer logins of the gitea users, if commit authors are matched to them
+	if cmt.Author != nil && cmt.Author.Login != "" {
+		res.Author.Username = cmt.Author.Login
+	}
+	if cmt.Committer != nil && cmt.Committer.Login != "" {
+		res.Committer.Username = cmt.Committer.Login
+	}
+
+	return res
+}
+
+func (g *Gitea) transformPR(pr giteaPR) git.PullRequest {
+	res := git.PullRequest{
+		Number: pr.Number,
+		Title:  pr.Title,
+		Body:   pr.Body,
+		Author: git.User{Username: pr.User.Login, Email: pr.User.Email},
+		Labels: lo.Map(pr.Labels, func(l giteaLabel, _ int) string { return l.Name }),
+		// closed at is set for pull requests closed without merging too,
+		// so we use merged at instead.
+		ClosedAt:     lo.FromPtr(pr.MergedAt),
+		SourceBranch: pr.Head.Ref,
+		TargetBranch: pr.Base.Ref,
+		URL:          pr.HTMLURL,
+	}
+
+	for _, assignee := range pr.Assignees {
+		res.Assignees = append(res.Assignees, git.User{Username: assignee.Login, Email: assignee.Email})
+	}
+
+	return res
+}
@@ -0,0 +1,226 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Semior001/releaseit/app/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitea_GetLastCommitOfBranch(t *testing.T) {
+	svc := newGitea(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v1/repos/owner/name/branches/branch", r.URL.Path)
+		_, err := w.Write([]byte(`{"name": "branch", "commit": {"id": "sha"}}`))
+		require.NoError(t, err)
+	})
+
+	sha, err := svc.GetLastCommitOfBranch(context.Background(), "branch")
+	require.NoError(t, err)
+	assert.Equal(t, "sha", sha)
+}
+
+func TestGitea_Compare(t *testing.T) {
+	svc := newGitea(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v1/repos/owner/name/compare/old...new", r.URL.Path)
+		_, err := w.Write([]byte(`{
+			"total_commits": 2,
+			"commits": [
+				{
+					"sha": "sha",
+					"html_url": "url",
+					"commit": {
+						"message": "message",
+						"author": {"name": "author", "email": "author@example.com", "date": "2020-01-01T00:00:00Z"},
+						"committer": {"name": "committer", "email": "committer@example.com", "date": "2020-01-02T00:00:00Z"}
+					},
+					"author": {"login": "author-login"},
+					"parents": [{"sha": "parent"}]
+				},
+				{
+					"sha": "sha2",
+					"commit": {"message": "message2"},
+					"parents": [{"sha": "parent2"}, {"sha": "parent3"}]
+				}
+			]
+		}`))
+		require.NoError(t, err)
+	})
+
+	comp, err := svc.Compare(context.Background(), "old", "new")
+	require.NoError(t, err)
+	assert.Equal(t, git.CommitsComparison{
+		Commits: []git.Commit{
+			{
+				SHA:         "sha",
+				ParentSHAs:  []string{"parent"},
+				Message:     "message",
+				CommittedAt: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+				AuthoredAt:  time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+				URL:         "url",
+				Author:      git.User{Username: "author-login", Email: "author@example.com"},
+				Committer:   git.User{Username: "committer", Email: "committer@example.com"},
+			},
+			{SHA: "sha2", ParentSHAs: []string{"parent2", "parent3"}, Message: "message2"},
+		},
+		TotalCommits: 2,
+	}, comp)
+}
+
+func TestGitea_Compare_Truncated(t *testing.T) {
+	// history: old <- c1 <- c2 <- c3 (new), compare returns only the last commit
+	svc := newGitea(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/repos/owner/name/compare/old...new":
+			_, err := w.Write([]byte(`{"total_commits": 3, "commits": [{"sha": "c3", "parents": [{"sha": "c2"}]}]}`))
+			require.NoError(t, err)
+		case "/api/v1/repos/owner/name/commits":
+			assert.Equal(t, "new", r.URL.Query().Get("sha"))
+			assert.Equal(t, "old", r.URL.Query().Get("not"))
+
+			var err error
+			switch r.URL.Query().Get("page") {
+			case "1":
+				_, err = w.Write([]byte(`[{"sha": "c3", "parents": [{"sha": "c2"}]}, {"sha": "c2", "parents": [{"sha": "c1"}]}]`))
+			case "2":
+				_, err = w.Write([]byte(`[{"sha": "c1", "parents": [{"sha": "old"}]}, {"sha": "old", "parents": [{"sha": "c0"}]}]`))
+			default:
+				t.Fatalf("unexpected page %s", r.URL.Query().Get("page"))
+			}
+			require.NoError(t, err)
+		default:
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	comp, err := svc.Compare(context.Background(), "old", "new")
+	require.NoError(t, err)
+	assert.Equal(t, git.CommitsComparison{
+		Commits: []git.Commit{
+			{SHA: "c3", ParentSHAs: []string{"c2"}},
+			{SHA: "c2", ParentSHAs: []string{"c1"}},
+			{SHA: "c1", ParentSHAs: []string{"old"}},
+		},
+		TotalCommits: 3,
+	}, comp)
+}
+
+func TestGitea_ListPRsOfCommit(t *testing.T) {
+	t.Run("commit of pull request", func(t *testing.T) {
+		svc := newGitea(t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/api/v1/repos/owner/name/commits/sha/pull", r.URL.Path)
+			_, err := w.Write([]byte(`{
+				"number": 1,
+				"title": "title",
+				"body": "body",
+				"user": {"login": "author", "email": "author@example.com"},
+				"labels": [{"name": "label1"}, {"name": "label2"}],
+				"merged_at": "2020-01-01T00:00:00Z",
+				"head": {"ref": "feature"},
+				"base": {"ref": "master"},
+				"html_url": "url",
+				"assignees": [{"login": "assignee", "email": "assignee@example.com"}]
+			}`))
+			require.NoError(t, err)
+		})
+
+		prs, err := svc.ListPRsOfCommit(context.Background(), "sha")
+		require.NoError(t, err)
+		assert.Equal(t, []git.PullRequest{{
+			Number:       1,
+			Title:        "title",
+			Body:         "body",
+			Author:       git.User{Username: "author", Email: "author@example.com"},
+			Labels:       []string{"label1", "label2"},
+			ClosedAt:     time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			SourceBranch: "feature",
+			TargetBranch: "master",
+			URL:          "url",
+			Assignees:    []git.User{{Username: "assignee", Email: "assignee@example.com"}},
+		}}, prs)
+	})
+
+	t.Run("commit without pull request", func(t *testing.T) {
+		svc := newGitea(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		})
+
+		prs, err := svc.ListPRsOfCommit(context.Background(), "sha")
+		require.NoError(t, err)
+		assert.Empty(t, prs)
+	})
+
+	t.Run("unexpected error", func(t *testing.T) {
+		svc := newGitea(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte(`{"message": "oops"}`))
+			require.NoError(t, err)
+		})
+
+		_, err := svc.ListPRsOfCommit(context.Background(), "sha")
+		assert.EqualError(t, err, `get pull request of commit: unexpected status code 500, message: "oops"`)
+	})
+}
+
+func TestGitea_ListTags(t *testing.T) {
+	svc := newGitea(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v1/repos/owner/name/tags", r.URL.Path)
+		assert.Equal(t, strconv.Itoa(giteaPerPage), r.URL.Query().Get("limit"))
+
+		// server limits the page size to less than requested
+		switch r.URL.Query().Get("page") {
+		case "1":
+			_, err := w.Write([]byte(`[{"name": "v0.0.1", "commit": {"sha": "sha"}}, {"name": "v0.0.2", "commit": {"sha": "sha"}}]`))
+			require.NoError(t, err)
+		case "2":
+			_, err := w.Write([]byte(`[{"name": "v1.0.0", "commit": {"sha": "sha2", "created": "2020-01-01T00:00:00Z"}}]`))
+			require.NoError(t, err)
+		case "3":
+			_, err := w.Write([]byte(`[]`))
+			require.NoError(t, err)
+		default:
+			t.Fatalf("unexpected page %s", r.URL.Query().Get("page"))
+		}
+	})
+
+	tags, err := svc.ListTags(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []git.Tag{
+		{Name: "v0.0.1", Commit: git.Commit{SHA: "sha"}},
+		{Name: "v0.0.2", Commit: git.Commit{SHA: "sha"}},
+		{Name: "v1.0.0", Commit: git.Commit{SHA: "sha2", CommittedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}},
+	}, tags)
+}
+
+func newGitea(t *testing.T, h http.HandlerFunc) *Gitea {
+	t.Helper()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "token token", r.Header.Get("Authorization"), "token is not set")
+
+		if r.URL.Path == "/api/v1/repos/owner/name" {
+			_, err := w.Write([]byte(`{"id": 1}`))
+			require.NoError(t, err)
+			return
+		}
+
+		h(w, r)
+	}))
+	t.Cleanup(ts.Close)
+
+	svc, err := NewGitea(context.Background(), GiteaParams{
+		BaseURL:    ts.URL + "/",
+		Token:      "token",
+		Owner:      "owner",
+		Name:       "name",
+		HTTPClient: http.Client{Timeout: 5 * time.Second},
+	})
+	require.NoError(t, err)
+
+	return svc
+}
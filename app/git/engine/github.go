@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 
 	"github.com/Semior001/releaseit/app/git"
+	"github.com/Semior001/releaseit/app/git/ghclient"
 	"github.com/go-pkgz/requester"
 	"github.com/go-pkgz/requester/middleware"
 	"github.com/go-pkgz/requester/middleware/logger"
@@ -14,6 +16,14 @@ import (
 	"github.com/samber/lo"
 )
 
+const (
+	// githubPerPage is the maximum page size allowed by github API.
+	githubPerPage = 100
+	// githubMaxWalkPages is the maximum number of pages of commits to list,
+	// if github doesn't return all commits in comparison.
+	githubMaxWalkPages = 50
+)
+
 // Github implements Repository with github API below it.
 type Github struct {
 	cl    *gh.Client
@@ -25,9 +35,13 @@ type Github struct {
 type GithubParams struct {
 	Owner             string
 	Name              string
+	BaseURL           string // api url of github enterprise server, api.github.com by default
 	BasicAuthUsername string
 	BasicAuthPassword string
+	Token             string // personal access token
+	App               ghclient.AppParams
 	HTTPClient        http.Client
+	RateLimit         RateLimitParams
 }
 
 // NewGithub makes new instance of Github.
@@ -37,13 +51,31 @@ func NewGithub(ctx context.Context, params GithubParams) (*Github, error) {
 		name:  params.Name,
 	}
 
-	cl := requester.New(params.HTTPClient, logger.New(logger.Func(log.Printf), logger.Prefix("[DEBUG]")).Middleware)
+	httpCl := params.HTTPClient
+	rl := newRateLimiter("github", params.RateLimit, &httpCl)
 
-	if params.BasicAuthUsername != "" && params.BasicAuthPassword != "" {
+	cl := requester.New(httpCl,
+		logger.New(logger.Func(log.Printf), logger.Prefix("[DEBUG]")).Middleware,
+		rl.Middleware,
+	)
+
+	switch {
+	case params.Token != "":
+		cl.Use(middleware.Header("Authorization", "Bearer "+params.Token))
+	case !params.App.Empty():
+		inst, err := ghclient.NewInstallation(params.App, params.Owner, params.Name, params.BaseURL, params.HTTPClient)
+		if err != nil {
+			return nil, fmt.Errorf("prepare github app installation: %w", err)
+		}
+		cl.Use(inst.Middleware)
+	case params.BasicAuthUsername != "" && params.BasicAuthPassword != "":
 		cl.Use(middleware.BasicAuth(params.BasicAuthUsername, params.BasicAuthPassword))
 	}
 
-	svc.cl = gh.NewClient(cl.Client())
+	var err error
+	if svc.cl, err = ghclient.New(cl.Client(), params.BaseURL); err != nil {
+		return nil, err
+	}
 
 	ctx, cancel := context.WithTimeout(ctx, defaultPingTimeout)
 	defer cancel()
@@ -66,24 +98,151 @@ func (g *Github) GetLastCommitOfBranch(ctx context.Context, branchName string) (
 }
 
 // Compare two commits by their SHA.
+// Comparison is paginated, if github doesn't return all commits
+// in pages, commits are listed from the head commit down to the merge base.
 func (g *Github) Compare(ctx context.Context, fromSHA, toSHA string) (git.CommitsComparison, error) {
-	comp, _, err := g.cl.Repositories.CompareCommits(ctx, g.owner, g.name, fromSHA, toSHA)
-	if err != nil {
-		return git.CommitsComparison{}, fmt.Errorf("github returned error: %w", err)
+	var (
+		commits   []*gh.RepositoryCommit
+		total     int
+		mergeBase string
+	)
+
+	for page := 1; ; page++ {
+		comp, resp, err := g.compare(ctx, fromSHA, toSHA, page)
+		if err != nil {
+			return git.CommitsComparison{}, fmt.Errorf("github returned error: %w", err)
+		}
+
+		total, mergeBase = comp.GetTotalCommits(), comp.GetMergeBaseCommit().GetSHA()
+		commits = append(commits, comp.Commits...)
+
+		if len(commits) >= total || len(comp.Commits) == 0 || resp.NextPage == 0 {
+			break
+		}
 	}
 
-	commits := make([]git.Commit, len(comp.Commits))
+	if len(commits) < total {
+		log.Printf("[DEBUG] github returned %d out of %d commits in comparison between %s and %s, "+
+			"listing commits down to the merge base %s", len(commits), total, fromSHA, toSHA, mergeBase)
 
-	for i, commit := range comp.Commits {
-		commits[i] = g.transformCommit(commit)
+		var err error
+		if commits, err = g.commitsBetween(ctx, mergeBase, toSHA, total); err != nil {
+			return git.CommitsComparison{}, fmt.Errorf("list commits between %s and %s: %w", mergeBase, toSHA, err)
+		}
+	}
+
+	res := make([]git.Commit, len(commits))
+
+	for i, commit := range commits {
+		res[i] = g.transformCommit(commit)
 	}
 
 	return git.CommitsComparison{
-		Commits:      commits,
-		TotalCommits: comp.GetTotalCommits(),
+		Commits:      res,
+		TotalCommits: total,
 	}, nil
 }
 
+// compare makes a request to compare two commits with pagination,
+// as the library doesn't support options for this endpoint.
+func (g *Github) compare(ctx context.Context, fromSHA, toSHA string, page int) (*gh.CommitsComparison, *gh.Response, error) {
+	u := fmt.Sprintf("repos/%s/%s/compare/%s...%s?page=%d&per_page=%d",
+		g.owner, g.name, url.QueryEscape(fromSHA), url.QueryEscape(toSHA), page, githubPerPage)
+
+	req, err := g.cl.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("make request: %w", err)
+	}
+
+	comp := &gh.CommitsComparison{}
+	resp, err := g.cl.Do(ctx, req, comp)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return comp, resp, nil
+}
+
+// commitsBetween lists commits reachable from the head commit, but not
+// from the base one, i.e. the commits of the comparison. Commits are listed
+// from the head down, until the walk of parents from the head reveals
+// exactly the expected number of commits, or up to githubMaxWalkPages pages.
+func (g *Github) commitsBetween(ctx context.Context, baseSHA, headSHA string, expected int) ([]*gh.RepositoryCommit, error) {
+	var (
+		listed  []*gh.RepositoryCommit
+		parents = map[string][]string{}
+		tipSHA  string // the resolved head, as it might be an alias
+	)
+
+	opts := &gh.CommitsListOptions{SHA: headSHA, ListOptions: gh.ListOptions{PerPage: githubPerPage}}
+	for page := 1; ; page++ {
+		if page > githubMaxWalkPages {
+			return nil, fmt.Errorf("expected %d commits are not found in the last %d commits of %s",
+				expected, githubMaxWalkPages*githubPerPage, headSHA)
+		}
+
+		commits, resp, err := g.cl.Repositories.ListCommits(ctx, g.owner, g.name, opts)
+		if err != nil {
+			return nil, fmt.Errorf("list commits: %w", err)
+		}
+
+		for _, commit := range commits {
+			if tipSHA == "" {
+				tipSHA = commit.GetSHA()
+			}
+
+			listed = append(listed, commit)
+			parents[commit.GetSHA()] = lo.Map(commit.Parents, func(p *gh.Commit, _ int) string { return p.GetSHA() })
+		}
+
+		last := resp.NextPage == 0 || len(commits) == 0
+
+		// commits are listed in reverse chronological order, which doesn't
+		// match the topological one, if the dates are skewed, so the ancestry
+		// is checked against all the listed commits, not on the fly
+		wanted, complete := walkCommitsBetween(parents, baseSHA, tipSHA)
+		if last || complete && len(wanted) == expected {
+			return lo.Reverse(lo.Filter(listed, func(c *gh.RepositoryCommit, _ int) bool {
+				return wanted[c.GetSHA()]
+			})), nil
+		}
+
+		opts.Page = resp.NextPage
+	}
+}
+
+// walkCommitsBetween walks the known parents from the head commit down,
+// stopping at the ancestors of the base commit. The walk is complete, if
+// all the walked commits are known. The complete walk contains all the
+// commits of the comparison, and only them, if their number matches
+// the expected one, as ancestors of the base are excluded in such case.
+func walkCommitsBetween(parents map[string][]string, baseSHA, headSHA string) (res map[string]bool, complete bool) {
+	walk := func(from string, stop map[string]bool) (visited map[string]bool, complete bool) {
+		visited, complete = map[string]bool{}, true
+		queue := []string{from}
+		for len(queue) > 0 {
+			sha := queue[0]
+			queue = queue[1:]
+
+			if visited[sha] || stop[sha] {
+				continue
+			}
+			visited[sha] = true
+
+			ps, ok := parents[sha]
+			if !ok {
+				complete = false
+				continue
+			}
+			queue = append(queue, ps...)
+		}
+		return visited, complete
+	}
+
+	excluded, _ := walk(baseSHA, nil)
+	return walk(headSHA, excluded)
+}
+
 // ListPRsOfCommit returns pull requests associated with commit by the given SHA.
 func (g *Github) ListPRsOfCommit(ctx context.Context, sha string) ([]git.PullRequest, error) {
 	prs, _, err := g.cl.PullRequests.ListPullRequestsWithCommit(ctx, g.owner, g.name, sha, &gh.PullRequestListOptions{})
@@ -109,21 +268,27 @@ func (g *Github) ListPRsOfCommit(ctx context.Context, sha string) ([]git.PullReq
 
 // ListTags returns all tags of the repository.
 func (g *Github) ListTags(ctx context.Context) ([]git.Tag, error) {
-	tags, _, err := g.cl.Repositories.ListTags(ctx, g.owner, g.name, &gh.ListOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("github returned error: %w", err)
-	}
+	var res []git.Tag
 
-	res := make([]git.Tag, len(tags))
+	opts := &gh.ListOptions{PerPage: githubPerPage}
+	for {
+		tags, resp, err := g.cl.Repositories.ListTags(ctx, g.owner, g.name, opts)
+		if err != nil {
+			return nil, fmt.Errorf("github returned error: %w", err)
+		}
 
-	for i, tag := range tags {
-		res[i] = git.Tag{
-			Name:   tag.GetName(),
-			Commit: g.transformCommit(tag.GetCommit()),
+		for _, tag := range tags {
+			res = append(res, git.Tag{
+				Name:   tag.GetName(),
+				Commit: g.transformCommit(tag.GetCommit()),
+			})
 		}
-	}
 
-	return res, nil
+		if resp.NextPage == 0 {
+			return res, nil
+		}
+		opts.Page = resp.NextPage
+	}
 }
 
 type shaGetter interface {
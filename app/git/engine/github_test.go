@@ -3,6 +3,7 @@ package engine
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -63,6 +64,137 @@ func TestGithub_Compare(t *testing.T) {
 	}, comp)
 }
 
+func TestGithub_Compare_Paginated(t *testing.T) {
+	t.Run("walks pages of comparison", func(t *testing.T) {
+		svc := newGithub(t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/repos/owner/name/compare/old...new", r.URL.Path, "path is not set")
+			assert.Equal(t, "100", r.URL.Query().Get("per_page"))
+
+			page := r.URL.Query().Get("page")
+			if page == "1" {
+				w.Header().Set("Link", `<https://api.github.com/repos/owner/name/compare/old...new?page=2>; rel="next"`)
+			}
+
+			err := json.NewEncoder(w).Encode(gh.CommitsComparison{
+				Commits:      []*gh.RepositoryCommit{{SHA: gh.String("sha" + page)}},
+				TotalCommits: gh.Int(2),
+			})
+			require.NoError(t, err)
+		})
+
+		comp, err := svc.Compare(context.Background(), "old", "new")
+		require.NoError(t, err)
+		assert.Equal(t, git.CommitsComparison{
+			Commits:      []git.Commit{{SHA: "sha1", ParentSHAs: []string{}}, {SHA: "sha2", ParentSHAs: []string{}}},
+			TotalCommits: 2,
+		}, comp)
+	})
+
+	t.Run("falls back to listing commits", func(t *testing.T) {
+		svc := newGithub(t, func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/repos/owner/name/compare/old...new":
+				err := json.NewEncoder(w).Encode(gh.CommitsComparison{
+					MergeBaseCommit: &gh.RepositoryCommit{SHA: gh.String("base")},
+					Commits:         []*gh.RepositoryCommit{{SHA: gh.String("fix")}},
+					TotalCommits:    gh.Int(6),
+				})
+				require.NoError(t, err)
+			case "/repos/owner/name/commits":
+				assert.Equal(t, "new", r.URL.Query().Get("sha"))
+				err := json.NewEncoder(w).Encode([]*gh.RepositoryCommit{
+					{SHA: gh.String("c3"), Parents: []*gh.Commit{{SHA: gh.String("c2")}, {SHA: gh.String("side")}}},
+					{SHA: gh.String("unrelated"), Parents: []*gh.Commit{{SHA: gh.String("base")}}},
+					{SHA: gh.String("c2"), Parents: []*gh.Commit{{SHA: gh.String("c1")}}},
+					// side branch is merged with the fix, branched before the base,
+					// which in turn merges the history of a vendored library
+					{SHA: gh.String("side"), Parents: []*gh.Commit{{SHA: gh.String("c1")}, {SHA: gh.String("fix")}}},
+					{SHA: gh.String("c1"), Parents: []*gh.Commit{{SHA: gh.String("base")}}},
+					{SHA: gh.String("base"), Parents: []*gh.Commit{{SHA: gh.String("old")}}},
+					{SHA: gh.String("fix"), Parents: []*gh.Commit{{SHA: gh.String("old")}, {SHA: gh.String("vendored")}}},
+					{SHA: gh.String("old")},
+					{SHA: gh.String("vendored")},
+				})
+				require.NoError(t, err)
+			default:
+				t.Fatalf("unexpected path %s", r.URL.Path)
+			}
+		})
+
+		comp, err := svc.Compare(context.Background(), "old", "new")
+		require.NoError(t, err)
+		assert.Equal(t, 6, comp.TotalCommits)
+		assert.Equal(t, []string{"vendored", "fix", "c1", "side", "c2", "c3"}, lo.Map(comp.Commits, func(c git.Commit, _ int) string { return c.SHA }))
+	})
+
+	t.Run("ancestors of the base with skewed dates", func(t *testing.T) {
+		svc := newGithub(t, func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/repos/owner/name/compare/old...new":
+				err := json.NewEncoder(w).Encode(gh.CommitsComparison{
+					MergeBaseCommit: &gh.RepositoryCommit{SHA: gh.String("base")},
+					TotalCommits:    gh.Int(3),
+				})
+				require.NoError(t, err)
+			case "/repos/owner/name/commits":
+				var commits []*gh.RepositoryCommit
+				switch page := r.URL.Query().Get("page"); page {
+				case "":
+					// the ancestor of the base is committed with the date in future
+					w.Header().Set("Link", `<https://api.github.com/repos/owner/name/commits?sha=new&page=2>; rel="next"`)
+					commits = []*gh.RepositoryCommit{
+						{SHA: gh.String("c2"), Parents: []*gh.Commit{{SHA: gh.String("c1")}, {SHA: gh.String("feat")}}},
+						{SHA: gh.String("feat"), Parents: []*gh.Commit{{SHA: gh.String("skewed")}}},
+						{SHA: gh.String("skewed")},
+					}
+				case "2":
+					w.Header().Set("Link", `<https://api.github.com/repos/owner/name/commits?sha=new&page=3>; rel="next"`)
+					commits = []*gh.RepositoryCommit{
+						{SHA: gh.String("c1"), Parents: []*gh.Commit{{SHA: gh.String("base")}}},
+						{SHA: gh.String("base"), Parents: []*gh.Commit{{SHA: gh.String("skewed")}}},
+					}
+				default:
+					t.Fatalf("unexpected page %s, all commits are already listed", page)
+				}
+				require.NoError(t, json.NewEncoder(w).Encode(commits))
+			default:
+				t.Fatalf("unexpected path %s", r.URL.Path)
+			}
+		})
+
+		comp, err := svc.Compare(context.Background(), "old", "new")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"c1", "feat", "c2"}, lo.Map(comp.Commits, func(c git.Commit, _ int) string { return c.SHA }))
+	})
+
+	t.Run("merge base is too deep", func(t *testing.T) {
+		pages := 0
+		svc := newGithub(t, func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/repos/owner/name/compare/old...new":
+				err := json.NewEncoder(w).Encode(gh.CommitsComparison{
+					MergeBaseCommit: &gh.RepositoryCommit{SHA: gh.String("base")},
+					TotalCommits:    gh.Int(2),
+				})
+				require.NoError(t, err)
+			case "/repos/owner/name/commits":
+				pages++
+				w.Header().Set("Link", fmt.Sprintf(`<https://api.github.com/repos/owner/name/commits?sha=new&page=%d>; rel="next"`, pages+1))
+				err := json.NewEncoder(w).Encode([]*gh.RepositoryCommit{
+					{SHA: gh.String(fmt.Sprintf("c%d", pages)), Parents: []*gh.Commit{{SHA: gh.String(fmt.Sprintf("c%d", pages+1))}}},
+				})
+				require.NoError(t, err)
+			default:
+				t.Fatalf("unexpected path %s", r.URL.Path)
+			}
+		})
+
+		_, err := svc.Compare(context.Background(), "old", "new")
+		assert.EqualError(t, err, "list commits between base and new: expected 2 commits are not found in the last 5000 commits of new")
+		assert.Equal(t, githubMaxWalkPages, pages)
+	})
+}
+
 func TestGithub_ListPRsOfCommit(t *testing.T) {
 	now := time.Now()
 
@@ -178,6 +310,49 @@ func TestGithub_ListTags(t *testing.T) {
 	}, tags)
 }
 
+func TestGithub_ListTags_Paginated(t *testing.T) {
+	svc := newGithub(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/repos/owner/name/tags", r.URL.Path, "path is not set")
+		assert.Equal(t, "100", r.URL.Query().Get("per_page"))
+
+		page := r.URL.Query().Get("page")
+		if page == "" {
+			w.Header().Set("Link", `<https://api.github.com/repos/owner/name/tags?page=2>; rel="next"`)
+		}
+
+		err := json.NewEncoder(w).Encode([]*gh.RepositoryTag{{
+			Name:   gh.String("tag" + page),
+			Commit: &gh.Commit{SHA: gh.String("sha" + page)},
+		}})
+		require.NoError(t, err)
+	})
+
+	tags, err := svc.ListTags(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []git.Tag{
+		{Name: "tag", Commit: git.Commit{SHA: "sha", ParentSHAs: []string{}}},
+		{Name: "tag2", Commit: git.Commit{SHA: "sha2", ParentSHAs: []string{}}},
+	}, tags)
+}
+
+func TestNewGithub_Enterprise(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.Equal(t, "/api/v3/repos/owner/name", r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	_, err := NewGithub(context.Background(), GithubParams{
+		Owner:      "owner",
+		Name:       "name",
+		BaseURL:    ts.URL,
+		Token:      "token",
+		HTTPClient: http.Client{Timeout: 5 * time.Second},
+	})
+	require.NoError(t, err)
+}
+
 func newGithub(t *testing.T, h http.HandlerFunc) *Github {
 	t.Helper()
 
@@ -14,6 +14,9 @@ import (
 	gl "gitlab.com/gitlab-org/api/client-go"
 )
 
+// gitlabPerPage is the maximum page size allowed by gitlab API.
+const gitlabPerPage = 100
+
 // Gitlab implements Repository with gitlab API below it.
 type Gitlab struct {
 	cl        *gl.Client
@@ -21,9 +24,14 @@ type Gitlab struct {
 }
 
 // NewGitlab creates a new Gitlab engine.
-func NewGitlab(ctx context.Context, token, baseURL, projectID string, httpCl http.Client) (*Gitlab, error) {
+func NewGitlab(ctx context.Context, token, baseURL, projectID string, httpCl http.Client,
+	rateLimit RateLimitParams) (*Gitlab, error) {
 	var (
-		cl  = requester.New(httpCl, logger.New(logger.Func(log.Printf), logger.Prefix("[DEBUG]")).Middleware)
+		rl = newRateLimiter("gitlab", rateLimit, &httpCl)
+		cl = requester.New(httpCl,
+			logger.New(logger.Func(log.Printf), logger.Prefix("[DEBUG]")).Middleware,
+			rl.Middleware,
+		)
 		svc = &Gitlab{projectID: projectID}
 		err error
 	)
@@ -32,6 +40,8 @@ func NewGitlab(ctx context.Context, token, baseURL, projectID string, httpCl htt
 		token,
 		gl.WithBaseURL(baseURL),
 		gl.WithHTTPClient(cl.Client()),
+		// retries are made by the rate limiter, only for idempotent requests
+		gl.WithoutRetries(),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("initialize gitlab client: %w", err)
@@ -98,22 +108,31 @@ func (g *Gitlab) ListPRsOfCommit(ctx context.Context, sha string) ([]git.PullReq
 
 // ListTags returns all tags of the repository.
 func (g *Gitlab) ListTags(ctx context.Context) ([]git.Tag, error) {
-	opts := &gl.ListTagsOptions{OrderBy: new("updated"), Sort: new("desc")}
-	tags, _, err := g.cl.Tags.ListTags(g.projectID, opts, gl.WithContext(ctx))
-	if err != nil {
-		return nil, fmt.Errorf("github returned error: %w", err)
+	var res []git.Tag
+
+	opts := &gl.ListTagsOptions{
+		ListOptions: gl.ListOptions{PerPage: gitlabPerPage},
+		OrderBy:     new("updated"),
+		Sort:        new("desc"),
 	}
+	for {
+		tags, resp, err := g.cl.Tags.ListTags(g.projectID, opts, gl.WithContext(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("gitlab returned error: %w", err)
+		}
 
-	res := make([]git.Tag, len(tags))
+		for _, tag := range tags {
+			res = append(res, git.Tag{
+				Name:   tag.Name,
+				Commit: g.transformCommit(tag.Commit),
+			})
+		}
 
-	for i, tag := range tags {
-		res[i] = git.Tag{
-			Name:   tag.Name,
-			Commit: g.transformCommit(tag.Commit),
+		if resp.NextPage == 0 {
+			return res, nil
 		}
+		opts.Page = resp.NextPage
 	}
-
-	return res, nil
 }
 
 func (g *Gitlab) transformCommit(commit *gl.Commit) git.Commit {
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/Semior001/releaseit/app/git"
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	gl "gitlab.com/gitlab-org/api/client-go"
@@ -128,6 +129,33 @@ func TestGitlab_ListTags(t *testing.T) {
 	}}, tags)
 }
 
+func TestGitlab_ListTags_Paginated(t *testing.T) {
+	svc := newGitlab(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v4/projects/projectID/repository/tags", r.URL.Path)
+		assert.Equal(t, "100", r.URL.Query().Get("per_page"))
+
+		page := r.URL.Query().Get("page")
+		if page == "" {
+			w.Header().Set("X-Next-Page", "2")
+		}
+
+		w.WriteHeader(http.StatusOK)
+
+		err := json.NewEncoder(w).Encode([]*gl.Tag{{
+			Name:   "v1.0." + lo.Ternary(page == "", "1", page),
+			Commit: &gl.Commit{ID: "sha" + page},
+		}})
+		require.NoError(t, err)
+	})
+
+	tags, err := svc.ListTags(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []git.Tag{
+		{Name: "v1.0.1", Commit: git.Commit{SHA: "sha"}},
+		{Name: "v1.0.2", Commit: git.Commit{SHA: "sha2"}},
+	}, tags)
+}
+
 func newGitlab(t *testing.T, h http.HandlerFunc) *Gitlab {
 	t.Helper()
 
@@ -158,7 +186,7 @@ func newGitlab(t *testing.T, h http.HandlerFunc) *Gitlab {
 		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
 			return http.DefaultTransport.RoundTrip(req)
 		}),
-	})
+	}, RateLimitParams{})
 	require.NoError(t, err)
 
 	return svc
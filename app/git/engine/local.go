@@ -0,0 +1,259 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Semior001/releaseit/app/git"
+)
+
+// Local implements Repository over the on-disk git repository,
+// by shelling out to the git binary.
+type Local struct {
+	LocalParams
+}
+
+// LocalParams contains parameters for local engine.
+type LocalParams struct {
+	// Path to the working tree or bare repository.
+	Path string
+	// PRs is an optional remote engine to look up pull requests of commits.
+	// If not set, pull requests are derived from merge commit messages.
+	PRs Interface
+	// GitBinary is the path to the git executable, "git" by default.
+	GitBinary string
+	// Remote is the name of the remote, whose tracking branches are
+	// checked, if there is no local branch, "origin" by default.
+	Remote string
+}
+
+// NewLocal makes new instance of Local.
+func NewLocal(ctx context.Context, params LocalParams) (*Local, error) {
+	if params.GitBinary == "" {
+		params.GitBinary = "git"
+	}
+
+	if params.Path == "" {
+		params.Path = "."
+	}
+
+	if params.Remote == "" {
+		params.Remote = "origin"
+	}
+
+	svc := &Local{LocalParams: params}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultPingTimeout)
+	defer cancel()
+
+	if _, err := svc.git(ctx, "rev-parse", "--git-dir"); err != nil {
+		return nil, fmt.Errorf("check git repository at %s: %w", params.Path, err)
+	}
+
+	return svc, nil
+}
+
+// GetLastCommitOfBranch returns the SHA or alias of the last commit in the branch.
+// If there is no local branch with such name, remote-tracking branches are checked.
+func (l *Local) GetLastCommitOfBranch(ctx context.Context, branch string) (string, error) {
+	for _, ref := range []string{"refs/heads/" + branch, "refs/remotes/" + l.Remote + "/" + branch} {
+		out, err := l.git(ctx, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+		if err == nil {
+			return strings.TrimSpace(out), nil
+		}
+	}
+
+	return "", fmt.Errorf("branch %s not found", branch)
+}
+
+// Compare two commits by their SHA.
+func (l *Local) Compare(ctx context.Context, fromSHA, toSHA string) (git.CommitsComparison, error) {
+	// refs are given by user, so they must not be interpreted as options
+	out, err := l.git(ctx, "log", "--reverse", "--format="+localCommitFormat, "--end-of-options", fromSHA+".."+toSHA, "--")
+	if err != nil {
+		return git.CommitsComparison{}, fmt.Errorf("git log: %w", err)
+	}
+
+	commits, err := l.parseCommits(out)
+	if err != nil {
+		return git.CommitsComparison{}, fmt.Errorf("parse commits: %w", err)
+	}
+
+	return git.CommitsComparison{
+		Commits:      commits,
+		TotalCommits: len(commits),
+	}, nil
+}
+
+// ListPRsOfCommit returns pull requests associated with commit by the given SHA.
+// If remote engine is not set, the pull request is derived from the commit message.
+func (l *Local) ListPRsOfCommit(ctx context.Context, sha string) ([]git.PullRequest, error) {
+	if l.PRs != nil {
+		return l.PRs.ListPRsOfCommit(ctx, sha)
+	}
+
+	out, err := l.git(ctx, "log", "-1", "--format="+localCommitFormat, "--end-of-options", sha, "--")
+	if err != nil {
+		return nil, fmt.Errorf("git log: %w", err)
+	}
+
+	commits, err := l.parseCommits(out)
+	if err != nil {
+		return nil, fmt.Errorf("parse commit: %w", err)
+	}
+
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("commit %s not found", sha)
+	}
+
+	pr, ok := prFromCommit(commits[0])
+	if !ok {
+		return nil, nil
+	}
+
+	return []git.PullRequest{pr}, nil
+}
+
+// ListTags returns all tags of the repository in descending order of creation.
+func (l *Local) ListTags(ctx context.Context) ([]git.Tag, error) {
+	out, err := l.git(ctx, "for-each-ref", "--sort=-creatordate",
+		"--format=%(refname:short)%1f%(objectname)%1f%(*objectname)", "refs/tags")
+	if err != nil {
+		return nil, fmt.Errorf("git for-each-ref: %w", err)
+	}
+
+	var res []git.Tag
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if line == "" {
+			continue
+		}
+
+		parts := strings.Split(line, "\x1f")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("unexpected tag line %q", line)
+		}
+
+		// annotated tags point to the tag object, so we take the dereferenced SHA
+		sha := parts[1]
+		if parts[2] != "" {
+			sha = parts[2]
+		}
+
+		res = append(res, git.Tag{Name: parts[0], Commit: git.Commit{SHA: sha}})
+	}
+
+	return res, nil
+}
+
+// fields are separated by the unit separator, commits by the record separator
+const localCommitFormat = "%H%x1f%P%x1f%an%x1f%ae%x1f%aI%x1f%cn%x1f%ce%x1f%cI%x1f%B%x1e"
+
+func (l *Local) parseCommits(out string) ([]git.Commit, error) {
+	var res []git.Commit
+	for _, rec := range strings.Split(out, "\x1e") {
+		rec = strings.TrimLeft(rec, "\n")
+		if rec == "" {
+			continue
+		}
+
+		fields := strings.SplitN(rec, "\x1f", 9)
+		if len(fields) != 9 {
+			return nil, fmt.Errorf("unexpected commit record %q", rec)
+		}
+
+		authoredAt, err := time.Parse(time.RFC3339, fields[4])
+		if err != nil {
+			return nil, fmt.Errorf("parse author date of %s: %w", fields[0], err)
+		}
+
+		committedAt, err := time.Parse(time.RFC3339, fields[7])
+		if err != nil {
+			return nil, fmt.Errorf("parse committer date of %s: %w", fields[0], err)
+		}
+
+		res = append(res, git.Commit{
+			SHA:         fields[0],
+			ParentSHAs:  strings.Fields(fields[1]),
+			Message:     strings.TrimRight(fields[8], "\n"),
+			CommittedAt: committedAt,
+			AuthoredAt:  authoredAt,
+			Author:      git.User{Username: fields[2], Email: fields[3]},
+			Committer:   git.User{Username: fields[5], Email: fields[6]},
+		})
+	}
+
+	return res, nil
+}
+
+func (l *Local) git(ctx context.Context, args ...string) (string, error) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+
+	cmd := exec.CommandContext(ctx, l.GitBinary, append([]string{"-C", l.Path}, args...)...) //nolint:gosec // args are controlled
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+
+	log.Printf("[DEBUG] running git %s", strings.Join(args, " "))
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("run git %s: %w, stderr: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
+
+var (
+	// Merge pull request #123 from owner/branch
+	ghMergeRx = regexp.MustCompile(`^Merge pull request #(\d+) from (\S+)`)
+	// See merge request group/project!123
+	glMergeRx = regexp.MustCompile(`(?m)^See merge request \S*!(\d+)$`)
+	// Merge branch 'source' into 'target'
+	glBranchesRx = regexp.MustCompile(`^Merge branch '([^']+)' into '([^']+)'`)
+	// Some squashed title (#123)
+	squashRx = regexp.MustCompile(`^(.*) \(#(\d+)\)$`)
+)
+
+// prFromCommit derives pull request from the message of merge or squash commit.
+func prFromCommit(commit git.Commit) (git.PullRequest, bool) {
+	subject, body, _ := strings.Cut(commit.Message, "\n")
+	body = strings.TrimSpace(body)
+
+	pr := git.PullRequest{
+		Author:   commit.Author,
+		ClosedAt: commit.CommittedAt,
+	}
+
+	switch {
+	case ghMergeRx.MatchString(subject):
+		m := ghMergeRx.FindStringSubmatch(subject)
+		pr.Number, _ = strconv.Atoi(m[1])
+		// github puts the owner as the prefix of the source branch
+		if _, branch, ok := strings.Cut(m[2], "/"); ok {
+			pr.SourceBranch = branch
+		}
+		pr.Title, pr.Body, _ = strings.Cut(body, "\n")
+	case glMergeRx.MatchString(body):
+		m := glMergeRx.FindStringSubmatch(body)
+		pr.Number, _ = strconv.Atoi(m[1])
+		if bm := glBranchesRx.FindStringSubmatch(subject); bm != nil {
+			pr.SourceBranch, pr.TargetBranch = bm[1], bm[2]
+		}
+		body = strings.TrimSpace(glMergeRx.ReplaceAllString(body, ""))
+		pr.Title, pr.Body, _ = strings.Cut(body, "\n")
+	case squashRx.MatchString(subject):
+		m := squashRx.FindStringSubmatch(subject)
+		pr.Number, _ = strconv.Atoi(m[2])
+		pr.Title, pr.Body = m[1], body
+	default:
+		return git.PullRequest{}, false
+	}
+
+	pr.Title, pr.Body = strings.TrimSpace(pr.Title), strings.TrimSpace(pr.Body)
+
+	return pr, true
+}
@@ -0,0 +1,180 @@
+package engine
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Semior001/releaseit/app/git"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocal_Compare(t *testing.T) {
+	svc, run := newLocal(t)
+
+	run("commit", "--allow-empty", "-m", "initial")
+	run("tag", "v0.1.0")
+	run("checkout", "-b", "feature/awesome")
+	run("commit", "--allow-empty", "-m", "awesome feature")
+	run("checkout", "master")
+	run("merge", "--no-ff", "feature/awesome", "-m", "Merge pull request #1 from owner/feature/awesome\n\nAwesome feature")
+	run("commit", "--allow-empty", "-m", "fix something (#2)")
+
+	comp, err := svc.Compare(context.Background(), "v0.1.0", "master")
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, comp.TotalCommits)
+	assert.Equal(t, []string{
+		"awesome feature",
+		"Merge pull request #1 from owner/feature/awesome\n\nAwesome feature",
+		"fix something (#2)",
+	}, lo.Map(comp.Commits, func(c git.Commit, _ int) string { return c.Message }))
+	assert.Len(t, comp.Commits[1].ParentSHAs, 2)
+	assert.Equal(t, git.User{Username: "releaseit", Email: "releaseit@example.com"}, comp.Commits[0].Author)
+	assert.False(t, comp.Commits[0].CommittedAt.IsZero())
+
+	// refs are not interpreted as options
+	out := filepath.Join(t.TempDir(), "out")
+	_, err = svc.Compare(context.Background(), "--output="+out, "master")
+	require.Error(t, err)
+	assert.NoFileExists(t, out)
+}
+
+func TestLocal_GetLastCommitOfBranch(t *testing.T) {
+	svc, run := newLocal(t)
+
+	run("commit", "--allow-empty", "-m", "initial")
+	expected := strings.TrimSpace(run("rev-parse", "HEAD"))
+
+	sha, err := svc.GetLastCommitOfBranch(context.Background(), "master")
+	require.NoError(t, err)
+	assert.Equal(t, expected, sha)
+
+	_, err = svc.GetLastCommitOfBranch(context.Background(), "unknown")
+	assert.EqualError(t, err, "branch unknown not found")
+
+	run("update-ref", "refs/remotes/origin/develop", expected)
+	run("update-ref", "refs/remotes/upstream/release", expected)
+
+	sha, err = svc.GetLastCommitOfBranch(context.Background(), "develop")
+	require.NoError(t, err)
+	assert.Equal(t, expected, sha)
+
+	svc.Remote = "upstream"
+	sha, err = svc.GetLastCommitOfBranch(context.Background(), "release")
+	require.NoError(t, err)
+	assert.Equal(t, expected, sha)
+
+	_, err = svc.GetLastCommitOfBranch(context.Background(), "develop")
+	assert.EqualError(t, err, "branch develop not found")
+}
+
+func TestLocal_ListTags(t *testing.T) {
+	svc, run := newLocal(t)
+
+	run("commit", "--allow-empty", "-m", "initial", "--date", "2020-01-01T00:00:00Z")
+	sha1 := strings.TrimSpace(run("rev-parse", "HEAD"))
+	run("tag", "v0.1.0")
+
+	run("commit", "--allow-empty", "-m", "second")
+	sha2 := strings.TrimSpace(run("rev-parse", "HEAD"))
+	run("tag", "-a", "v0.2.0", "-m", "annotated")
+
+	tags, err := svc.ListTags(context.Background())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []git.Tag{
+		{Name: "v0.2.0", Commit: git.Commit{SHA: sha2}},
+		{Name: "v0.1.0", Commit: git.Commit{SHA: sha1}},
+	}, tags)
+}
+
+func TestLocal_ListPRsOfCommit(t *testing.T) {
+	t.Run("derived from commit messages", func(t *testing.T) {
+		svc, run := newLocal(t)
+
+		run("commit", "--allow-empty", "-m", "initial")
+		run("commit", "--allow-empty", "-m", "Merge pull request #1 from owner/feature/awesome\n\nAwesome feature\n\nBody")
+		ghSHA := strings.TrimSpace(run("rev-parse", "HEAD"))
+		run("commit", "--allow-empty", "-m", "Merge branch 'fix/bug' into 'master'\n\nFix bug\n\nSee merge request group/project!2")
+		glSHA := strings.TrimSpace(run("rev-parse", "HEAD"))
+		run("commit", "--allow-empty", "-m", "chore: update deps (#3)")
+		squashSHA := strings.TrimSpace(run("rev-parse", "HEAD"))
+		run("commit", "--allow-empty", "-m", "regular commit")
+		regularSHA := strings.TrimSpace(run("rev-parse", "HEAD"))
+
+		prs, err := svc.ListPRsOfCommit(context.Background(), ghSHA)
+		require.NoError(t, err)
+		require.Len(t, prs, 1)
+		assert.Equal(t, 1, prs[0].Number)
+		assert.Equal(t, "Awesome feature", prs[0].Title)
+		assert.Equal(t, "Body", prs[0].Body)
+		assert.Equal(t, "feature/awesome", prs[0].SourceBranch)
+		assert.False(t, prs[0].ClosedAt.IsZero())
+
+		prs, err = svc.ListPRsOfCommit(context.Background(), glSHA)
+		require.NoError(t, err)
+		require.Len(t, prs, 1)
+		assert.Equal(t, 2, prs[0].Number)
+		assert.Equal(t, "Fix bug", prs[0].Title)
+		assert.Equal(t, "fix/bug", prs[0].SourceBranch)
+		assert.Equal(t, "master", prs[0].TargetBranch)
+
+		prs, err = svc.ListPRsOfCommit(context.Background(), squashSHA)
+		require.NoError(t, err)
+		require.Len(t, prs, 1)
+		assert.Equal(t, 3, prs[0].Number)
+		assert.Equal(t, "chore: update deps", prs[0].Title)
+
+		prs, err = svc.ListPRsOfCommit(context.Background(), regularSHA)
+		require.NoError(t, err)
+		assert.Empty(t, prs)
+	})
+
+	t.Run("delegated to remote engine", func(t *testing.T) {
+		svc, _ := newLocal(t)
+		svc.PRs = &InterfaceMock{
+			ListPRsOfCommitFunc: func(ctx context.Context, sha string) ([]git.PullRequest, error) {
+				assert.Equal(t, "sha", sha)
+				return []git.PullRequest{{Number: 1}}, nil
+			},
+		}
+
+		prs, err := svc.ListPRsOfCommit(context.Background(), "sha")
+		require.NoError(t, err)
+		assert.Equal(t, []git.PullRequest{{Number: 1}}, prs)
+	})
+}
+
+func newLocal(t *testing.T) (svc *Local, run func(args ...string) string) {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	run = func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(cmd.Environ(),
+			"GIT_AUTHOR_NAME=releaseit", "GIT_AUTHOR_EMAIL=releaseit@example.com",
+			"GIT_COMMITTER_NAME=releaseit", "GIT_COMMITTER_EMAIL=releaseit@example.com",
+			"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1",
+		)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git %v: %s", args, out)
+		return string(out)
+	}
+
+	run("init", "--initial-branch", "master")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	svc, err := NewLocal(ctx, LocalParams{Path: dir})
+	require.NoError(t, err)
+
+	return svc, run
+}
@@ -0,0 +1,210 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-pkgz/requester/middleware"
+)
+
+// RateLimitParams describes how requests, which failed due to the rate
+// limit or transient server error, are retried.
+type RateLimitParams struct {
+	Attempts int           // including the first one, 5 by default
+	Delay    time.Duration // the delay before the first retry, doubled on each retry, 1s by default
+	// the upper bound of the delay, 1m by default,
+	// request isn't retried, if the server asks to wait longer
+	MaxDelay time.Duration
+}
+
+// rateLimiter retries idempotent requests, which failed due to the rate
+// limit or transient server error, waiting until the rate limit is reset
+// or with exponential backoff, if the server didn't specify the time to wait.
+type rateLimiter struct {
+	RateLimitParams
+	name string // name of the engine, for logging purposes
+	// timeout of each attempt, waiting between attempts is
+	// limited only by the context of the request
+	timeout time.Duration
+}
+
+// newRateLimiter makes a rate limiter, which takes over the timeout
+// of the client to apply it to each attempt, as the timeout of the client
+// limits the whole round trip with all retries.
+func newRateLimiter(name string, params RateLimitParams, cl *http.Client) *rateLimiter {
+	if params.Attempts <= 0 {
+		params.Attempts = 5
+	}
+
+	if params.Delay <= 0 {
+		params.Delay = time.Second
+	}
+
+	if params.MaxDelay <= 0 {
+		params.MaxDelay = time.Minute
+	}
+
+	l := &rateLimiter{RateLimitParams: params, name: name, timeout: cl.Timeout}
+	cl.Timeout = 0
+
+	return l
+}
+
+// Middleware returns the middleware for the requester.
+func (l *rateLimiter) Middleware(next http.RoundTripper) http.RoundTripper {
+	return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			return l.roundTrip(next, req)
+		}
+
+		delay := l.Delay
+		for attempt := 1; ; attempt++ {
+			resp, err := l.roundTrip(next, req)
+			if err != nil {
+				return nil, err
+			}
+
+			l.logQuota(resp)
+
+			wait, retry := l.waitFor(resp, delay)
+			if !retry || attempt >= l.Attempts {
+				return resp, nil
+			}
+
+			// rate limit might be reset in an hour, it's pointless to hang that long
+			if wait > l.MaxDelay {
+				log.Printf("[WARN] %s responded with %d, rate limit is reset in %s, which exceeds the max delay %s",
+					l.name, resp.StatusCode, wait.Round(time.Second), l.MaxDelay)
+				return resp, nil
+			}
+
+			if deadline, ok := req.Context().Deadline(); ok && time.Now().Add(wait).After(deadline) {
+				log.Printf("[WARN] %s responded with %d, no time left to retry in %s", l.name, resp.StatusCode, wait)
+				return resp, nil
+			}
+
+			log.Printf("[WARN] %s responded with %d, attempt %d/%d, retrying in %s",
+				l.name, resp.StatusCode, attempt, l.Attempts, wait)
+
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+			_ = resp.Body.Close()
+
+			timer := time.NewTimer(wait)
+			select {
+			case <-req.Context().Done():
+				timer.Stop()
+				return nil, req.Context().Err()
+			case <-timer.C:
+			}
+
+			delay = min(delay*2, l.MaxDelay)
+		}
+	})
+}
+
+// roundTrip makes a single attempt within the timeout, the timeout
+// is released, when the body of the response is closed.
+func (l *rateLimiter) roundTrip(next http.RoundTripper, req *http.Request) (*http.Response, error) {
+	if l.timeout <= 0 {
+		return next.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), l.timeout)
+	resp, err := next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	resp.Body = cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelOnClose cancels the context of the request, when the body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close closes the body and cancels the context of the request.
+func (c cancelOnClose) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
+
+// waitFor checks whether the request must be retried and how long to wait
+// before the next attempt.
+func (l *rateLimiter) waitFor(resp *http.Response, delay time.Duration) (time.Duration, bool) {
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests, isRateLimited(resp):
+		// secondary rate limits of github specify the time to wait in Retry-After
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			return time.Duration(secs) * time.Second, true
+		}
+
+		// primary rate limits specify the time, when the quota is reset
+		if rateLimitHeader(resp, "Remaining") == "0" {
+			if reset, err := strconv.ParseInt(rateLimitHeader(resp, "Reset"), 10, 64); err == nil {
+				return max(time.Until(time.Unix(reset, 0)), 0), true
+			}
+		}
+
+		return delay, true
+	case resp.StatusCode >= http.StatusInternalServerError && resp.StatusCode != http.StatusNotImplemented:
+		return delay, true
+	}
+
+	return 0, false
+}
+
+// isRateLimited checks whether the 403 response is caused by the rate limit,
+// github responds with 403 both on exceeded primary and secondary rate limits.
+func isRateLimited(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusForbidden {
+		return false
+	}
+
+	if rateLimitHeader(resp, "Remaining") == "0" || resp.Header.Get("Retry-After") != "" {
+		return true
+	}
+
+	// secondary rate limit might be only mentioned in the message,
+	// the body is restored to be read by the caller
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{Reader: io.MultiReader(bytes.NewReader(b), resp.Body), Closer: resp.Body}
+
+	return strings.Contains(strings.ToLower(string(b)), "rate limit")
+}
+
+func (l *rateLimiter) logQuota(resp *http.Response) {
+	remaining := rateLimitHeader(resp, "Remaining")
+	if remaining == "" {
+		return
+	}
+
+	resetAt := "unknown"
+	if reset, err := strconv.ParseInt(rateLimitHeader(resp, "Reset"), 10, 64); err == nil {
+		resetAt = time.Unix(reset, 0).Format(time.RFC3339)
+	}
+
+	log.Printf("[DEBUG] %s rate limit: %s/%s requests remaining, resets at %s",
+		l.name, remaining, rateLimitHeader(resp, "Limit"), resetAt)
+}
+
+// rateLimitHeader returns the value of the rate limit header, github
+// prefixes them with "X-", while gitlab doesn't.
+func rateLimitHeader(resp *http.Response, name string) string {
+	if v := resp.Header.Get("X-RateLimit-" + name); v != "" {
+		return v
+	}
+	return resp.Header.Get("RateLimit-" + name)
+}
@@ -0,0 +1,230 @@
+package engine
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-pkgz/requester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter_Middleware(t *testing.T) {
+	tbl := []struct {
+		name      string
+		method    string
+		responses []func(w http.ResponseWriter)
+		wantCalls int32
+		wantCode  int
+		wantBody  string
+	}{
+		{
+			name:   "primary rate limit",
+			method: http.MethodGet,
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.Header().Set("X-RateLimit-Remaining", "0")
+					w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(-time.Second).Unix(), 10))
+					w.WriteHeader(http.StatusForbidden)
+				},
+				func(w http.ResponseWriter) { _, _ = w.Write([]byte("ok")) },
+			},
+			wantCalls: 2,
+			wantCode:  http.StatusOK,
+			wantBody:  "ok",
+		},
+		{
+			name:   "primary rate limit reset exceeds max delay",
+			method: http.MethodGet,
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.Header().Set("X-RateLimit-Remaining", "0")
+					w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+					w.WriteHeader(http.StatusForbidden)
+					_, _ = w.Write([]byte("rate limit exceeded"))
+				},
+			},
+			wantCalls: 1,
+			wantCode:  http.StatusForbidden,
+			wantBody:  "rate limit exceeded",
+		},
+		{
+			name:   "secondary rate limit",
+			method: http.MethodGet,
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.WriteHeader(http.StatusForbidden)
+					_, _ = w.Write([]byte(`{"message": "You have exceeded a secondary rate limit."}`))
+				},
+				func(w http.ResponseWriter) {
+					w.Header().Set("Retry-After", "0")
+					w.WriteHeader(http.StatusTooManyRequests)
+				},
+				func(w http.ResponseWriter) { _, _ = w.Write([]byte("ok")) },
+			},
+			wantCalls: 3,
+			wantCode:  http.StatusOK,
+			wantBody:  "ok",
+		},
+		{
+			name:   "gitlab rate limit",
+			method: http.MethodGet,
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.Header().Set("RateLimit-Remaining", "0")
+					w.Header().Set("RateLimit-Reset", strconv.FormatInt(time.Now().Unix(), 10))
+					w.WriteHeader(http.StatusTooManyRequests)
+				},
+				func(w http.ResponseWriter) { _, _ = w.Write([]byte("ok")) },
+			},
+			wantCalls: 2,
+			wantCode:  http.StatusOK,
+			wantBody:  "ok",
+		},
+		{
+			name:   "server errors exceeded attempts",
+			method: http.MethodGet,
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusBadGateway) },
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusBadGateway) },
+				func(w http.ResponseWriter) {
+					w.WriteHeader(http.StatusBadGateway)
+					_, _ = w.Write([]byte("bad gateway"))
+				},
+			},
+			wantCalls: 3,
+			wantCode:  http.StatusBadGateway,
+			wantBody:  "bad gateway",
+		},
+		{
+			name:   "forbidden without rate limit",
+			method: http.MethodGet,
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.WriteHeader(http.StatusForbidden)
+					_, _ = w.Write([]byte(`{"message": "Resource not accessible by integration"}`))
+				},
+			},
+			wantCalls: 1,
+			wantCode:  http.StatusForbidden,
+			wantBody:  `{"message": "Resource not accessible by integration"}`,
+		},
+		{
+			name:   "not idempotent",
+			method: http.MethodPost,
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusServiceUnavailable) },
+			},
+			wantCalls: 1,
+			wantCode:  http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tbl {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := atomic.AddInt32(&calls, 1)
+				require.LessOrEqual(t, int(n), len(tt.responses))
+				tt.responses[n-1](w)
+			}))
+			defer ts.Close()
+
+			httpCl := http.Client{}
+			rl := newRateLimiter("test", RateLimitParams{Attempts: 3, Delay: time.Millisecond, MaxDelay: 2 * time.Millisecond}, &httpCl)
+			cl := requester.New(httpCl, rl.Middleware).Client()
+
+			req, err := http.NewRequest(tt.method, ts.URL, http.NoBody)
+			require.NoError(t, err)
+
+			resp, err := cl.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantCalls, atomic.LoadInt32(&calls))
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+			assert.Equal(t, tt.wantBody, string(body))
+		})
+	}
+}
+
+func TestRateLimiter_Middleware_Deadline(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	httpCl := http.Client{}
+	rl := newRateLimiter("test", RateLimitParams{}, &httpCl)
+	cl := requester.New(httpCl, rl.Middleware).Client()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL, http.NoBody)
+	require.NoError(t, err)
+
+	resp, err := cl.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestRateLimiter_Middleware_ClientTimeout(t *testing.T) {
+	newClient := func() *http.Client {
+		httpCl := http.Client{Timeout: 500 * time.Millisecond}
+		rl := newRateLimiter("test", RateLimitParams{Attempts: 3}, &httpCl)
+		return requester.New(httpCl, rl.Middleware).Client()
+	}
+
+	t.Run("waiting for retry is not limited", func(t *testing.T) {
+		var calls int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				// waiting for the retry takes longer than the timeout of the client
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			_, err := w.Write([]byte("ok"))
+			require.NoError(t, err)
+		}))
+		defer ts.Close()
+
+		resp, err := newClient().Get(ts.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "ok", string(body))
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("attempt is limited", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+		}))
+		defer ts.Close()
+
+		_, err := newClient().Get(ts.URL)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,223 @@
+// Package ghclient contains helpers to build clients for github and
+// github enterprise server APIs and to authenticate as a GitHub App.
+package ghclient
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	gh "github.com/google/go-github/v37/github"
+)
+
+// New makes new github client, which talks to the given API base URL,
+// or to api.github.com, if the base URL is empty. The upload URL is
+// derived from the base one.
+func New(cl *http.Client, baseURL string) (*gh.Client, error) {
+	if baseURL == "" {
+		return gh.NewClient(cl), nil
+	}
+
+	res, err := gh.NewEnterpriseClient(baseURL, baseURL, cl)
+	if err != nil {
+		return nil, fmt.Errorf("make enterprise client for %s: %w", baseURL, err)
+	}
+
+	res.UploadURL = enterpriseUploadURL(*res.BaseURL)
+
+	return res, nil
+}
+
+// enterpriseUploadURL returns the upload URL of the server by its API URL,
+// e.g. https://api.example.ghe.com/ for GHE.com and https://github.example.com/api/v3/
+// for GHE server have uploads at https://uploads.example.ghe.com/ and
+// https://github.example.com/api/uploads/ respectively.
+func enterpriseUploadURL(u url.URL) *url.URL {
+	if host, ok := strings.CutPrefix(u.Host, "api."); ok {
+		u.Host = "uploads." + host
+		return &u
+	}
+
+	u.Path = strings.TrimSuffix(strings.TrimSuffix(u.Path, "/api/v3/"), "/") + "/api/uploads/"
+	return &u
+}
+
+// AppParams describes the GitHub App to authenticate as.
+type AppParams struct {
+	ID             int64
+	InstallationID int64  // if empty, installation is looked up by the repository
+	PrivateKey     []byte // PEM-encoded private key of the app
+}
+
+// Empty returns true if the app is not specified.
+func (p AppParams) Empty() bool { return p.ID == 0 }
+
+// tokenExpiryGap is the gap before the expiration of the installation
+// token, when the token is considered expired and is refreshed.
+const tokenExpiryGap = time.Minute
+
+// Installation authenticates requests with the tokens of the GitHub App
+// installation, tokens are issued on demand and refreshed when expired.
+type Installation struct {
+	AppParams
+	owner string
+	name  string
+
+	key *rsa.PrivateKey
+	cl  *gh.Client // authenticated as app with JWT
+	now func() time.Time
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// NewInstallation makes new instance of Installation. Owner and name of the
+// repository are used to look up the installation, if its ID is not specified.
+func NewInstallation(params AppParams, owner, name, baseURL string, cl http.Client) (*Installation, error) {
+	key, err := parsePrivateKey(params.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("parse private key: %w", err)
+	}
+
+	svc := &Installation{AppParams: params, owner: owner, name: name, key: key, now: time.Now}
+
+	next := cl.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	cl.Transport = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		jwt, err := svc.jwt()
+		if err != nil {
+			return nil, fmt.Errorf("make jwt: %w", err)
+		}
+
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", "Bearer "+jwt)
+		return next.RoundTrip(req)
+	})
+
+	if svc.cl, err = New(&cl, baseURL); err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// Middleware sets the installation token to the request's authorization header.
+func (i *Installation) Middleware(next http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		token, err := i.Token(req.Context())
+		if err != nil {
+			return nil, fmt.Errorf("get installation token: %w", err)
+		}
+
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", "token "+token)
+		return next.RoundTrip(req)
+	})
+}
+
+// Token returns the installation token, issuing the new one if
+// the previous is expired.
+func (i *Installation) Token(ctx context.Context) (string, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.token != "" && i.now().Add(tokenExpiryGap).Before(i.expiresAt) {
+		return i.token, nil
+	}
+
+	if i.InstallationID == 0 {
+		inst, _, err := i.cl.Apps.FindRepositoryInstallation(ctx, i.owner, i.name)
+		if err != nil {
+			return "", fmt.Errorf("find installation of %s/%s: %w", i.owner, i.name, err)
+		}
+		i.InstallationID = inst.GetID()
+	}
+
+	token, _, err := i.cl.Apps.CreateInstallationToken(ctx, i.InstallationID, nil)
+	if err != nil {
+		return "", fmt.Errorf("create installation token: %w", err)
+	}
+
+	i.token, i.expiresAt = token.GetToken(), token.GetExpiresAt()
+	return i.token, nil
+}
+
+// jwt makes a JSON Web Token to authenticate as the app.
+func (i *Installation) jwt() (string, error) {
+	now := i.now()
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", fmt.Errorf("marshal header: %w", err)
+	}
+
+	claims, err := json.Marshal(map[string]any{
+		// backdate to protect against clock drift, as github suggests
+		"iat": now.Add(-time.Minute).Unix(),
+		// github doesn't allow tokens living longer than 10 minutes
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": strconv.FormatInt(i.ID, 10),
+	})
+	if err != nil {
+		return "", fmt.Errorf("marshal claims: %w", err)
+	}
+
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+
+	hash := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, i.key, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", fmt.Errorf("sign: %w", err)
+	}
+
+	return unsigned + "." + enc.EncodeToString(sig), nil
+}
+
+// parsePrivateKey parses PEM-encoded RSA private key in PKCS#1 or PKCS#8 format.
+func parsePrivateKey(b []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse key: %w", err)
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("expected RSA private key, got %T", key)
+	}
+
+	return rsaKey, nil
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,135 @@
+package ghclient
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	cl, err := New(http.DefaultClient, "")
+	require.NoError(t, err)
+	assert.Equal(t, "https://api.github.com/", cl.BaseURL.String())
+
+	cl, err = New(http.DefaultClient, "https://github.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.example.com/api/v3/", cl.BaseURL.String())
+	assert.Equal(t, "https://github.example.com/api/uploads/", cl.UploadURL.String())
+
+	cl, err = New(http.DefaultClient, "https://github.example.com/api/v3/")
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.example.com/api/v3/", cl.BaseURL.String())
+	assert.Equal(t, "https://github.example.com/api/uploads/", cl.UploadURL.String())
+
+	cl, err = New(http.DefaultClient, "https://api.example.ghe.com")
+	require.NoError(t, err)
+	assert.Equal(t, "https://api.example.ghe.com/", cl.BaseURL.String())
+	assert.Equal(t, "https://uploads.example.ghe.com/", cl.UploadURL.String())
+}
+
+func TestInstallation_Middleware(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	tokensIssued := 0
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/repos/owner/name/installation":
+			checkJWT(t, &key.PublicKey, r, now)
+			_, err := w.Write([]byte(`{"id": 42}`))
+			require.NoError(t, err)
+		case "/api/v3/app/installations/42/access_tokens":
+			require.Equal(t, http.MethodPost, r.Method)
+			checkJWT(t, &key.PublicKey, r, now)
+			tokensIssued++
+			w.WriteHeader(http.StatusCreated)
+			err := json.NewEncoder(w).Encode(map[string]any{
+				"token":      "installation-token",
+				"expires_at": now.Add(time.Hour),
+			})
+			require.NoError(t, err)
+		case "/api/v3/repos/owner/name":
+			assert.Equal(t, "token installation-token", r.Header.Get("Authorization"))
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	inst, err := NewInstallation(AppParams{
+		ID:         1,
+		PrivateKey: pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}),
+	}, "owner", "name", ts.URL, http.Client{Timeout: 5 * time.Second})
+	require.NoError(t, err)
+	inst.now = func() time.Time { return now }
+
+	cl := &http.Client{Transport: inst.Middleware(http.DefaultTransport)}
+
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, ts.URL+"/api/v3/repos/owner/name", http.NoBody)
+		require.NoError(t, err)
+		resp, err := cl.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+	assert.Equal(t, 1, tokensIssued, "token must be cached")
+	assert.Equal(t, int64(42), inst.InstallationID)
+
+	// token is refreshed, when it's about to expire
+	now = now.Add(time.Hour - time.Second)
+	_, err = inst.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, tokensIssued)
+}
+
+func TestNewInstallation_InvalidKey(t *testing.T) {
+	_, err := NewInstallation(AppParams{ID: 1, PrivateKey: []byte("not a key")}, "owner", "name", "", http.Client{})
+	assert.EqualError(t, err, "parse private key: no PEM block found")
+}
+
+func checkJWT(t *testing.T, pub *rsa.PublicKey, r *http.Request, now time.Time) {
+	t.Helper()
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	require.True(t, ok, "jwt is not set")
+
+	parts := strings.Split(token, ".")
+	require.Len(t, parts, 3)
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	require.NoError(t, err)
+
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	require.NoError(t, rsa.VerifyPKCS1v15(pub, crypto.SHA256, hash[:], sig))
+
+	b, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, err)
+
+	var claims struct {
+		IssuedAt  int64  `json:"iat"`
+		ExpiresAt int64  `json:"exp"`
+		Issuer    string `json:"iss"`
+	}
+	require.NoError(t, json.Unmarshal(b, &claims))
+	assert.Equal(t, "1", claims.Issuer)
+	assert.Equal(t, now.Add(-time.Minute).Unix(), claims.IssuedAt)
+	assert.Equal(t, now.Add(9*time.Minute).Unix(), claims.ExpiresAt)
+}
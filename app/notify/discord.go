@@ -0,0 +1,128 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+)
+
+const (
+	// discordContentLimit is the maximum length of the message content.
+	discordContentLimit = 2000
+	// discordEmbedLimit is the maximum length of the embed description.
+	discordEmbedLimit = 4096
+	// discordFenceReserve is the room, left in each part to close
+	// and re-open the code block, which is cut between parts.
+	discordFenceReserve = 32
+)
+
+// Discord sends messages to Discord via webhook, release notes, which
+// don't fit into a single message, are split across multiple messages.
+type Discord struct {
+	DiscordParams
+}
+
+// DiscordParams describes parameters to initialize discord notifier.
+type DiscordParams struct {
+	Log      *log.Logger
+	Client   http.Client
+	URL      string
+	Username string // overrides the default username of the webhook
+	Title    string // title of the first embed
+	Color    int    // color of the embeds' border
+	// PlainText sends release notes as a message content instead of embeds.
+	PlainText bool
+}
+
+// NewDiscord makes a new Discord notifier.
+func NewDiscord(params DiscordParams) *Discord {
+	return &Discord{DiscordParams: params}
+}
+
+// String returns the name of the notifier.
+func (d *Discord) String() string {
+	return fmt.Sprintf("discord hook at: %s", extractBaseURL(d.URL))
+}
+
+// Send sends a message to Discord.
+func (d *Discord) Send(ctx context.Context, text string) error {
+	limit := discordEmbedLimit
+	if d.PlainText {
+		limit = discordContentLimit
+	}
+
+	chunks := splitText(text, limit-discordFenceReserve)
+
+	fence := "" // opening line of the code block, cut by the previous chunk
+	for idx, chunk := range chunks {
+		chunk = fence + chunk
+		if fence = mdOpenFence(chunk); fence != "" {
+			chunk += "\n```"
+		}
+
+		msg := discordMsg{Username: d.Username}
+
+		if d.PlainText {
+			msg.Content = chunk
+		} else {
+			embed := discordEmbed{Description: chunk, Color: d.Color}
+			if idx == 0 {
+				embed.Title = d.Title
+			}
+			msg.Embeds = []discordEmbed{embed}
+		}
+
+		if err := retryRequest(ctx, func() error { return d.send(ctx, msg) }); err != nil {
+			return fmt.Errorf("send part %d/%d: %w", idx+1, len(chunks), err)
+		}
+	}
+
+	return nil
+}
+
+func (d *Discord) send(ctx context.Context, msg discordMsg) error {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshal body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("do request: %w", err)
+	}
+	defer func() {
+		if err = resp.Body.Close(); err != nil {
+			d.Log.Printf("[WARN] can't close request body, %s", err)
+		}
+	}()
+
+	// discord responds with 204, unless wait=true is specified in the url
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return checkRetryable(resp, fmt.Errorf("unexpected status code: %d, message: %q", resp.StatusCode, msg))
+	}
+
+	return nil
+}
+
+type discordMsg struct {
+	Username string         `json:"username,omitempty"`
+	Content  string         `json:"content,omitempty"`
+	Embeds   []discordEmbed `json:"embeds,omitempty"`
+}
+
+type discordEmbed struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description"`
+	Color       int    `json:"color,omitempty"`
+}
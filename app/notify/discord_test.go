@@ -0,0 +1,106 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscord_Send(t *testing.T) {
+	t.Run("embeds", func(t *testing.T) {
+		var msgs []discordMsg
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/webhooks/123/token", r.URL.Path)
+			assert.Equal(t, http.MethodPost, r.Method)
+
+			var msg discordMsg
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+			msgs = append(msgs, msg)
+
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer ts.Close()
+
+		line := strings.Repeat("a", 3000)
+		svc := NewDiscord(DiscordParams{
+			Log:      log.Default(),
+			URL:      ts.URL + "/api/webhooks/123/token",
+			Username: "releaseit",
+			Title:    "v1.0.0",
+			Color:    0x00ff00,
+		})
+		require.NoError(t, svc.Send(context.Background(), line+"\n"+line+"\nend"))
+
+		assert.Equal(t, []discordMsg{
+			{Username: "releaseit", Embeds: []discordEmbed{{Title: "v1.0.0", Description: line, Color: 0x00ff00}}},
+			{Username: "releaseit", Embeds: []discordEmbed{{Description: line + "\nend", Color: 0x00ff00}}},
+		}, msgs)
+	})
+
+	t.Run("plain text", func(t *testing.T) {
+		var msgs []discordMsg
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var msg discordMsg
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+			msgs = append(msgs, msg)
+
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer ts.Close()
+
+		line := strings.Repeat("a", 1500)
+		svc := NewDiscord(DiscordParams{Log: log.Default(), URL: ts.URL, Title: "ignored", PlainText: true})
+		require.NoError(t, svc.Send(context.Background(), line+"\n"+line))
+
+		assert.Equal(t, []discordMsg{{Content: line}, {Content: line}}, msgs)
+	})
+
+	t.Run("code block cut between parts", func(t *testing.T) {
+		var msgs []discordMsg
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var msg discordMsg
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+			msgs = append(msgs, msg)
+
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer ts.Close()
+
+		line := strings.Repeat("a", 1000)
+		svc := NewDiscord(DiscordParams{Log: log.Default(), URL: ts.URL, PlainText: true})
+		require.NoError(t, svc.Send(context.Background(), "notes\n```go\n"+line+"\n"+line+"\n```\nend"))
+
+		assert.Equal(t, []discordMsg{
+			{Content: "notes\n```go\n" + line + "\n```"},
+			{Content: "```go\n" + line + "\n```\nend"},
+		}, msgs)
+		for _, msg := range msgs {
+			assert.LessOrEqual(t, len(msg.Content), discordContentLimit)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, err := w.Write([]byte(`{"message": "Invalid Form Body"}`))
+			require.NoError(t, err)
+		}))
+		defer ts.Close()
+
+		svc := NewDiscord(DiscordParams{Log: log.Default(), URL: ts.URL})
+		err := svc.Send(context.Background(), "release notes")
+		assert.EqualError(t, err, `send part 1/1: unexpected status code: 400, message: "{\"message\": \"Invalid Form Body\"}"`)
+	})
+}
+
+func TestDiscord_String(t *testing.T) {
+	svc := NewDiscord(DiscordParams{URL: "https://discord.com/api/webhooks/123/token"})
+	assert.Equal(t, "discord hook at: https://discord.com", svc.String())
+}
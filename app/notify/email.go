@@ -0,0 +1,276 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Semior001/releaseit/app/service/eval"
+)
+
+// EmailSecurity specifies how the connection to the SMTP server is secured.
+type EmailSecurity string
+
+// Supported connection security modes.
+const (
+	// EmailSecurityNone sends emails over the plain connection, credentials
+	// are allowed to be sent over it only to the local server.
+	EmailSecurityNone EmailSecurity = "none"
+	// EmailSecuritySTARTTLS upgrades the plain connection with STARTTLS command.
+	EmailSecuritySTARTTLS EmailSecurity = "starttls"
+	// EmailSecurityTLS connects to the server over TLS, usually on port 465.
+	EmailSecurityTLS EmailSecurity = "tls"
+)
+
+// Email sends release notes via SMTP.
+type Email struct {
+	EmailParams
+}
+
+// EmailParams describes parameters to initialize email notifier.
+type EmailParams struct {
+	Log       *log.Logger
+	Evaluator *eval.Evaluator
+	Host      string
+	Port      int
+	Security  EmailSecurity
+	Username  string // if empty, no authentication is performed
+	Password  string
+	From      string
+	To        []string
+	// SubjectTmplText is the template of the email subject,
+	// it has access to the release fields, e.g. .Version or .PRs.
+	SubjectTmplText string
+	Extras          map[string]string
+	Timeout         time.Duration
+	// InsecureSkipVerify disables verification of the server's certificate.
+	InsecureSkipVerify bool
+}
+
+// NewEmail makes a new Email notifier.
+func NewEmail(params EmailParams) (*Email, error) {
+	if _, err := mail.ParseAddress(params.From); err != nil {
+		return nil, fmt.Errorf("parse sender address %q: %w", params.From, err)
+	}
+
+	if len(params.To) == 0 {
+		return nil, fmt.Errorf("no recipients specified")
+	}
+
+	for _, to := range params.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			return nil, fmt.Errorf("parse recipient address %q: %w", to, err)
+		}
+	}
+
+	// PLAIN auth would expose credentials over the unencrypted connection,
+	// so it's allowed only for the local server, the same as in smtp.PlainAuth
+	if params.Security == EmailSecurityNone && params.Username != "" && !isLocalhost(params.Host) {
+		return nil, fmt.Errorf("credentials can't be sent to %s over unencrypted connection, "+
+			"use starttls or tls security", params.Host)
+	}
+
+	if err := params.Evaluator.Validate(params.SubjectTmplText); err != nil {
+		return nil, fmt.Errorf("invalid subject template: %w", err)
+	}
+
+	return &Email{EmailParams: params}, nil
+}
+
+// String returns the name of the notifier.
+func (e *Email) String() string {
+	return fmt.Sprintf("email to %s via %s:%d", strings.Join(e.To, ", "), e.Host, e.Port)
+}
+
+type emailSubjectTmplData struct {
+	Release
+	Extras map[string]string
+}
+
+// Send sends the release notes by email.
+func (e *Email) Send(ctx context.Context, rel Release) error {
+	subject, err := e.Evaluator.Evaluate(ctx, e.SubjectTmplText, emailSubjectTmplData{Release: rel, Extras: e.Extras})
+	if err != nil {
+		return fmt.Errorf("build subject: %w", err)
+	}
+
+	msg, err := e.message(strings.TrimSpace(subject), rel.Text)
+	if err != nil {
+		return fmt.Errorf("build message: %w", err)
+	}
+
+	if err = e.send(ctx, msg); err != nil {
+		return fmt.Errorf("send email: %w", err)
+	}
+
+	e.Log.Printf("[INFO] sent email to %s", strings.Join(e.To, ", "))
+
+	return nil
+}
+
+func (e *Email) send(ctx context.Context, msg []byte) (err error) {
+	addr := net.JoinHostPort(e.Host, strconv.Itoa(e.Port))
+	dialer := &net.Dialer{Timeout: e.Timeout}
+	tlsCfg := &tls.Config{ServerName: e.Host, InsecureSkipVerify: e.InsecureSkipVerify} //nolint:gosec // set by user
+
+	var conn net.Conn
+	if e.Security == EmailSecurityTLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsCfg}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("dial %s: %w", addr, err)
+	}
+
+	// the whole smtp session is limited by the timeout too,
+	// as the context might not have a deadline
+	var deadline time.Time
+	if e.Timeout > 0 {
+		deadline = time.Now().Add(e.Timeout)
+	}
+	if ctxDeadline, ok := ctx.Deadline(); ok && (deadline.IsZero() || ctxDeadline.Before(deadline)) {
+		deadline = ctxDeadline
+	}
+	if !deadline.IsZero() {
+		if err = conn.SetDeadline(deadline); err != nil {
+			_ = conn.Close()
+			return fmt.Errorf("set deadline: %w", err)
+		}
+	}
+
+	cl, err := smtp.NewClient(conn, e.Host)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("make smtp client: %w", err)
+	}
+	defer func() {
+		// connection is already closed, if the session is finished with QUIT
+		if cerr := cl.Close(); cerr != nil && !errors.Is(cerr, net.ErrClosed) {
+			e.Log.Printf("[WARN] can't close smtp connection, %s", cerr)
+		}
+	}()
+
+	if e.Security == EmailSecuritySTARTTLS {
+		if ok, _ := cl.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("server doesn't support STARTTLS")
+		}
+
+		if err = cl.StartTLS(tlsCfg); err != nil {
+			return fmt.Errorf("start tls: %w", err)
+		}
+	}
+
+	if e.Username != "" {
+		if err = cl.Auth(smtp.PlainAuth("", e.Username, e.Password, e.Host)); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+	}
+
+	from, _ := mail.ParseAddress(e.From) // validated in constructor
+	if err = cl.Mail(from.Address); err != nil {
+		return fmt.Errorf("set sender: %w", err)
+	}
+
+	for _, to := range e.To {
+		rcpt, _ := mail.ParseAddress(to) // validated in constructor
+		if err = cl.Rcpt(rcpt.Address); err != nil {
+			return fmt.Errorf("set recipient %s: %w", rcpt.Address, err)
+		}
+	}
+
+	w, err := cl.Data()
+	if err != nil {
+		return fmt.Errorf("start data: %w", err)
+	}
+
+	if _, err = w.Write(msg); err != nil {
+		return fmt.Errorf("write message: %w", err)
+	}
+
+	if err = w.Close(); err != nil {
+		return fmt.Errorf("finish data: %w", err)
+	}
+
+	if err = cl.Quit(); err != nil {
+		return fmt.Errorf("quit: %w", err)
+	}
+
+	return nil
+}
+
+// message builds a multipart message with the markdown release notes
+// as a plain text part and its HTML rendering as an alternative.
+func (e *Email) message(subject, text string) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	mw := multipart.NewWriter(buf)
+
+	msgID := make([]byte, 16)
+	if _, err := rand.Read(msgID); err != nil {
+		return nil, fmt.Errorf("generate message id: %w", err)
+	}
+
+	hdr := []struct{ key, value string }{
+		{"From", e.From},
+		{"To", strings.Join(e.To, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", fmt.Sprintf("<%s@%s>", hex.EncodeToString(msgID), e.Host)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "multipart/alternative; boundary=" + mw.Boundary()},
+	}
+
+	for _, h := range hdr {
+		fmt.Fprintf(buf, "%s: %s\r\n", h.key, h.value)
+	}
+	buf.WriteString("\r\n")
+
+	parts := []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", "<html><body>\n" + markdownToHTML(text) + "\n</body></html>"},
+	}
+
+	for _, p := range parts {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {p.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("create part %s: %w", p.contentType, err)
+		}
+
+		qw := quotedprintable.NewWriter(pw)
+		if _, err = qw.Write([]byte(p.body)); err != nil {
+			return nil, fmt.Errorf("write part %s: %w", p.contentType, err)
+		}
+
+		if err = qw.Close(); err != nil {
+			return nil, fmt.Errorf("close part %s: %w", p.contentType, err)
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, fmt.Errorf("close multipart writer: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func isLocalhost(host string) bool {
+	return host == "localhost" || host == "127.0.0.1" || host == "::1"
+}
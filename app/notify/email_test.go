@@ -0,0 +1,341 @@
+package notify
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"io"
+	"log"
+	"math/big"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Semior001/releaseit/app/git"
+	"github.com/Semior001/releaseit/app/service/eval"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmail_Send(t *testing.T) {
+	tlsCfg := testTLSConfig(t)
+
+	tbl := []struct {
+		name     string
+		security EmailSecurity
+		username string
+		srvTLS   *tls.Config
+		implicit bool
+		wantCmds []string
+		wantErr  string
+	}{
+		{
+			name:     "plain",
+			security: EmailSecurityNone,
+			wantCmds: []string{"EHLO localhost", "MAIL FROM:<releaseit@example.com>",
+				"RCPT TO:<dev@example.com>", "RCPT TO:<pm@example.com>", "DATA", "QUIT"},
+		},
+		{
+			name:     "plain with auth to local server",
+			security: EmailSecurityNone,
+			username: "user",
+			wantCmds: []string{"EHLO localhost",
+				"AUTH PLAIN " + base64.StdEncoding.EncodeToString([]byte("\x00user\x00password")),
+				"MAIL FROM:<releaseit@example.com>", "RCPT TO:<dev@example.com>", "RCPT TO:<pm@example.com>",
+				"DATA", "QUIT"},
+		},
+		{
+			name:     "starttls with auth",
+			security: EmailSecuritySTARTTLS,
+			username: "user",
+			srvTLS:   tlsCfg,
+			wantCmds: []string{"EHLO localhost", "STARTTLS", "EHLO localhost",
+				"AUTH PLAIN " + base64.StdEncoding.EncodeToString([]byte("\x00user\x00password")),
+				"MAIL FROM:<releaseit@example.com>", "RCPT TO:<dev@example.com>", "RCPT TO:<pm@example.com>",
+				"DATA", "QUIT"},
+		},
+		{
+			name:     "implicit tls",
+			security: EmailSecurityTLS,
+			srvTLS:   tlsCfg,
+			implicit: true,
+			wantCmds: []string{"EHLO localhost", "MAIL FROM:<releaseit@example.com>",
+				"RCPT TO:<dev@example.com>", "RCPT TO:<pm@example.com>", "DATA", "QUIT"},
+		},
+		{
+			name:     "starttls not supported",
+			security: EmailSecuritySTARTTLS,
+			wantCmds: []string{"EHLO localhost", "QUIT"},
+			wantErr:  "send email: server doesn't support STARTTLS",
+		},
+	}
+
+	for _, tt := range tbl {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newFakeSMTP(t, tt.srvTLS, tt.implicit)
+
+			host, port, err := net.SplitHostPort(srv.ln.Addr().String())
+			require.NoError(t, err)
+			portNum, err := strconv.Atoi(port)
+			require.NoError(t, err)
+
+			svc, err := NewEmail(EmailParams{
+				Log:                log.Default(),
+				Evaluator:          &eval.Evaluator{},
+				Host:               host,
+				Port:               portNum,
+				Security:           tt.security,
+				Username:           tt.username,
+				Password:           "password",
+				From:               "Releaseit <releaseit@example.com>",
+				To:                 []string{"dev@example.com", "PM <pm@example.com>"},
+				SubjectTmplText:    `{{ .Extras.project }}: релиз {{ .Version }} ({{ len .PRs }} PR)`,
+				Extras:             map[string]string{"project": "releaseit"},
+				Timeout:            5 * time.Second,
+				InsecureSkipVerify: true,
+			})
+			require.NoError(t, err)
+
+			err = svc.Send(context.Background(), Release{
+				Version: "v1.0.0",
+				PRs:     []git.PullRequest{{Number: 1}, {Number: 2}},
+				Text:    "## Features\n- **new** feature",
+			})
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			cmds, data := srv.received()
+			assert.Equal(t, tt.wantCmds, cmds)
+
+			msg, err := mail.ReadMessage(strings.NewReader(data))
+			require.NoError(t, err)
+
+			assert.Equal(t, "Releaseit <releaseit@example.com>", msg.Header.Get("From"))
+			assert.Equal(t, "dev@example.com, PM <pm@example.com>", msg.Header.Get("To"))
+
+			subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+			require.NoError(t, err)
+			assert.Equal(t, "releaseit: релиз v1.0.0 (2 PR)", subject)
+
+			mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+			require.NoError(t, err)
+			assert.Equal(t, "multipart/alternative", mediaType)
+
+			mr := multipart.NewReader(msg.Body, params["boundary"])
+			for _, want := range []struct{ contentType, body string }{
+				{"text/plain; charset=utf-8", "## Features\n- **new** feature"},
+				{"text/html; charset=utf-8", "<html><body>\n<h2>Features</h2>\n<ul>\n" +
+					"<li><strong>new</strong> feature</li>\n</ul>\n</body></html>"},
+			} {
+				part, err := mr.NextPart()
+				require.NoError(t, err)
+				assert.Equal(t, want.contentType, part.Header.Get("Content-Type"))
+
+				body, err := io.ReadAll(part)
+				require.NoError(t, err)
+				assert.Equal(t, want.body, string(body))
+			}
+		})
+	}
+}
+
+func TestEmail_Send_Timeout(t *testing.T) {
+	// server accepts the connection, but never greets the client
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		t.Cleanup(func() { _ = conn.Close() })
+	}()
+
+	host, port, err := net.SplitHostPort(ln.Addr().String())
+	require.NoError(t, err)
+	portNum, err := strconv.Atoi(port)
+	require.NoError(t, err)
+
+	svc, err := NewEmail(EmailParams{
+		Log:       log.Default(),
+		Evaluator: &eval.Evaluator{},
+		Host:      host,
+		Port:      portNum,
+		Security:  EmailSecurityNone,
+		From:      "releaseit@example.com",
+		To:        []string{"dev@example.com"},
+		Timeout:   100 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	start := time.Now()
+	err = svc.Send(context.Background(), Release{Version: "v1.0.0"})
+	assert.ErrorContains(t, err, "i/o timeout")
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestNewEmail_Validation(t *testing.T) {
+	_, err := NewEmail(EmailParams{Evaluator: &eval.Evaluator{}, From: "invalid", To: []string{"dev@example.com"}})
+	assert.ErrorContains(t, err, `parse sender address "invalid"`)
+
+	_, err = NewEmail(EmailParams{Evaluator: &eval.Evaluator{}, From: "releaseit@example.com"})
+	assert.EqualError(t, err, "no recipients specified")
+
+	_, err = NewEmail(EmailParams{Evaluator: &eval.Evaluator{}, From: "releaseit@example.com",
+		To: []string{"dev@example.com"}, SubjectTmplText: "{{ .Extras"})
+	assert.ErrorContains(t, err, "invalid subject template")
+
+	_, err = NewEmail(EmailParams{Evaluator: &eval.Evaluator{}, From: "releaseit@example.com",
+		To: []string{"dev@example.com"}, Host: "smtp.example.com", Security: EmailSecurityNone, Username: "user"})
+	assert.EqualError(t, err, "credentials can't be sent to smtp.example.com over unencrypted connection, "+
+		"use starttls or tls security")
+}
+
+func TestEmail_String(t *testing.T) {
+	svc := &Email{EmailParams: EmailParams{Host: "smtp.example.com", Port: 587, To: []string{"a@example.com", "b@example.com"}}}
+	assert.Equal(t, "email to a@example.com, b@example.com via smtp.example.com:587", svc.String())
+}
+
+// fakeSMTP is a minimal SMTP server, which accepts all messages.
+type fakeSMTP struct {
+	t      *testing.T
+	ln     net.Listener
+	tlsCfg *tls.Config
+
+	mu   sync.Mutex
+	cmds []string
+	data string
+}
+
+func newFakeSMTP(t *testing.T, tlsCfg *tls.Config, implicitTLS bool) *fakeSMTP {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	if implicitTLS {
+		ln = tls.NewListener(ln, tlsCfg)
+		tlsCfg = nil // don't advertise STARTTLS over TLS connection
+	}
+
+	srv := &fakeSMTP{t: t, ln: ln, tlsCfg: tlsCfg}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go srv.serve(conn)
+		}
+	}()
+
+	return srv
+}
+
+func (s *fakeSMTP) received() (cmds []string, data string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cmds, s.data
+}
+
+func (s *fakeSMTP) serve(conn net.Conn) {
+	defer conn.Close()
+
+	tp := textproto.NewConn(conn)
+	write := func(lines ...string) {
+		for i, line := range lines {
+			sep := " "
+			if i < len(lines)-1 {
+				sep = "-"
+			}
+			assert.NoError(s.t, tp.PrintfLine("%s%s%s", line[:3], sep, line[4:]))
+		}
+	}
+
+	write("220 localhost fake ESMTP")
+
+	secured := false
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.cmds = append(s.cmds, line)
+		s.mu.Unlock()
+
+		switch cmd, _, _ := strings.Cut(line, " "); strings.ToUpper(cmd) {
+		case "EHLO":
+			if s.tlsCfg != nil && !secured {
+				write("250 localhost", "250 STARTTLS", "250 AUTH PLAIN")
+				continue
+			}
+			write("250 localhost", "250 AUTH PLAIN")
+		case "STARTTLS":
+			write("220 ready to start TLS")
+			tlsConn := tls.Server(conn, s.tlsCfg)
+			if err = tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, tp, secured = tlsConn, textproto.NewConn(tlsConn), true
+		case "AUTH":
+			write("235 authenticated")
+		case "MAIL", "RCPT":
+			write("250 ok")
+		case "DATA":
+			write("354 go ahead")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.data = string(data)
+			s.mu.Unlock()
+			write("250 queued")
+		case "QUIT":
+			write("221 bye")
+			return
+		default:
+			write("502 not implemented")
+		}
+	}
+}
+
+// testTLSConfig makes TLS config with self-signed certificate.
+func testTLSConfig(t *testing.T) *tls.Config {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	return &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}} //nolint:gosec // test
+}
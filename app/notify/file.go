@@ -0,0 +1,256 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/Semior001/releaseit/app/git"
+)
+
+// FileMode specifies how the release notes are written to the file.
+type FileMode string
+
+// Supported file modes.
+const (
+	// FileModeOverwrite replaces the contents of the file with release notes.
+	FileModeOverwrite FileMode = "overwrite"
+	// FileModeAppend writes release notes to the end of the file.
+	FileModeAppend FileMode = "append"
+	// FileModePrependAfterMarker inserts release notes right below the
+	// line with marker, so the latest release is always on top.
+	FileModePrependAfterMarker FileMode = "prepend-after-marker"
+)
+
+// DefaultFileMarker is the marker, below which release notes are inserted
+// in FileModePrependAfterMarker mode, if no other marker is specified.
+const DefaultFileMarker = "<!-- releaseit -->"
+
+// File writes release notes to the file, e.g. CHANGELOG.md.
+type File struct {
+	FileParams
+}
+
+// FileParams describes parameters to initialize file notifier.
+type FileParams struct {
+	Log    *log.Logger
+	Path   string
+	Mode   FileMode
+	Marker string // used only in FileModePrependAfterMarker mode
+}
+
+// NewFile makes a new File notifier.
+func NewFile(params FileParams) (*File, error) {
+	switch params.Mode {
+	case FileModeOverwrite, FileModeAppend:
+	case FileModePrependAfterMarker:
+		if params.Marker == "" {
+			params.Marker = DefaultFileMarker
+		}
+	default:
+		return nil, fmt.Errorf("unsupported file mode %q", params.Mode)
+	}
+
+	return &File{FileParams: params}, nil
+}
+
+// String returns the name of the notifier.
+func (f *File) String() string {
+	return fmt.Sprintf("file at %s (%s)", f.Path, f.Mode)
+}
+
+// Send writes release notes to the file. If the release contains commits,
+// release notes are prefixed with a hidden comment with the version and
+// the SHA of the last commit of the release, which is used to refuse
+// inserting the same release twice. The SHA is used, as the version might
+// be a reference, which moves between releases, e.g. HEAD or a branch.
+// Otherwise, the file is checked to contain the same release notes.
+// The file is left untouched, if it already contains the release notes.
+// If the file contains the same version released at another commit, e.g.
+// the tag was moved, its release notes are replaced, unless the version
+// is a reference, which moved forward right after that release.
+func (f *File) Send(_ context.Context, rel Release) error {
+	existing, err := os.ReadFile(f.Path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("read file: %w", err)
+	}
+	content := string(existing)
+
+	marker := releaseMarker(rel)
+
+	if f.Mode != FileModeOverwrite && f.contains(content, marker, rel.Text) {
+		if rel.Version != "" {
+			f.Log.Printf("[WARN] release notes for version %s are already in %s, skipping", rel.Version, f.Path)
+			return nil
+		}
+		f.Log.Printf("[WARN] release notes are already in %s, skipping", f.Path)
+		return nil
+	}
+
+	block := strings.TrimRight(rel.Text, "\n") + "\n"
+	if marker != "" {
+		block = marker + "\n" + block
+	}
+
+	start, end, replace := versionSection(content, rel)
+
+	switch {
+	case f.Mode == FileModeOverwrite:
+		content = block
+	case replace:
+		f.Log.Printf("[INFO] replacing release notes for version %s in %s", rel.Version, f.Path)
+		// keep the empty lines, which separate the section from the next one
+		section := content[start:end]
+		trailing := section[len(strings.TrimRight(section, "\n")):]
+		if trailing == "" {
+			trailing = "\n"
+		}
+		content = content[:start] + strings.TrimRight(block, "\n") + trailing + content[end:]
+	case f.Mode == FileModeAppend:
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		content += block
+	case f.Mode == FileModePrependAfterMarker:
+		if content, err = f.insertAfterMarker(content, block); err != nil {
+			return err
+		}
+	}
+
+	if err = os.WriteFile(f.Path, []byte(content), 0o644); err != nil { //nolint:gosec // changelog is not a secret
+		return fmt.Errorf("write file: %w", err)
+	}
+
+	return nil
+}
+
+// insertAfterMarker inserts block below the line with marker, separating
+// it with empty lines from the surrounding content. If the file is empty,
+// the marker is written on top of it.
+func (f *File) insertAfterMarker(content, block string) (string, error) {
+	if content == "" {
+		return f.Marker + "\n\n" + block, nil
+	}
+
+	idx := strings.Index(content, f.Marker)
+	if idx < 0 {
+		return "", fmt.Errorf("marker %q not found in %s", f.Marker, f.Path)
+	}
+
+	// move to the beginning of the line after the marker
+	pos := idx + len(f.Marker)
+	if nl := strings.IndexByte(content[pos:], '\n'); nl >= 0 {
+		pos += nl + 1
+	} else {
+		pos = len(content)
+	}
+
+	head, rest := content[:pos], content[pos:]
+	if !strings.HasSuffix(head, "\n") {
+		head += "\n"
+	}
+	if rest != "" && !strings.HasPrefix(rest, "\n") {
+		rest = "\n" + rest
+	}
+
+	return head + "\n" + block + rest, nil
+}
+
+func (f *File) contains(content, marker, text string) bool {
+	if marker != "" {
+		return strings.Contains(content, marker)
+	}
+	text = strings.TrimSpace(text)
+	return text != "" && strings.Contains(content, text)
+}
+
+// versionSection returns the bounds of the release notes of the same version,
+// released at another commit. The section spans from its marker to the marker
+// of the next release or to the end of the file. Nothing is returned, if the
+// release directly follows the existing one, i.e. the version is a reference,
+// which moved forward, e.g. HEAD, so the existing release notes are kept.
+func versionSection(content string, rel Release) (start, end int, ok bool) {
+	sha := lastCommitSHA(rel.Commits)
+	if sha == "" || rel.Version == "" || rel.Version == sha {
+		return 0, 0, false
+	}
+
+	members, parents := map[string]bool{}, map[string]bool{}
+	for _, c := range rel.Commits {
+		members[c.SHA] = true
+		for _, p := range c.ParentSHAs {
+			parents[p] = true
+		}
+	}
+
+	prefix := fmt.Sprintf("<!-- releaseit:%s@", rel.Version)
+	start = -1
+	for pos := 0; ; {
+		idx := strings.Index(content[pos:], prefix)
+		if idx < 0 {
+			break
+		}
+		idx += pos
+		pos = idx + len(prefix)
+
+		prevSHA, _, _ := strings.Cut(content[pos:], " -->")
+		if parents[prevSHA] && !members[prevSHA] {
+			return 0, 0, false
+		}
+
+		if start < 0 {
+			start = idx
+		}
+	}
+
+	if start < 0 {
+		return 0, 0, false
+	}
+
+	end = len(content)
+	if nl := strings.IndexByte(content[start:], '\n'); nl >= 0 {
+		if next := strings.Index(content[start+nl:], "\n<!-- releaseit:"); next >= 0 {
+			end = start + nl + next + 1
+		}
+	}
+
+	return start, end, true
+}
+
+// releaseMarker returns the hidden comment, which identifies the release
+// by the last commit of it, or an empty string, if the release has no commits.
+func releaseMarker(rel Release) string {
+	sha := lastCommitSHA(rel.Commits)
+	if sha == "" {
+		return ""
+	}
+
+	if rel.Version == "" || rel.Version == sha {
+		return fmt.Sprintf("<!-- releaseit:%s -->", sha)
+	}
+
+	return fmt.Sprintf("<!-- releaseit:%s@%s -->", rel.Version, sha)
+}
+
+// lastCommitSHA returns the SHA of the commit, which is not a parent of any
+// other commit in the list, regardless of the order of commits.
+func lastCommitSHA(commits []git.Commit) string {
+	parents := map[string]struct{}{}
+	for _, c := range commits {
+		for _, p := range c.ParentSHAs {
+			parents[p] = struct{}{}
+		}
+	}
+
+	for i := len(commits) - 1; i >= 0; i-- {
+		if _, ok := parents[commits[i].SHA]; !ok {
+			return commits[i].SHA
+		}
+	}
+
+	return ""
+}
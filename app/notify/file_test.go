@@ -0,0 +1,192 @@
+package notify
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Semior001/releaseit/app/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFile_Send(t *testing.T) {
+	tbl := []struct {
+		name     string
+		params   FileParams
+		version  string
+		sha      string // sha of the last commit of the release
+		existing *string
+		want     string // if empty, the file must be left untouched
+		wantErr  string
+	}{
+		{
+			name:     "overwrite",
+			params:   FileParams{Mode: FileModeOverwrite},
+			existing: new("old notes\n"),
+			want:     "## v1.1.0\n- feature\n",
+		},
+		{
+			name:    "overwrite, new file",
+			params:  FileParams{Mode: FileModeOverwrite},
+			version: "v1.1.0",
+			sha:     "sha2",
+			want:    "<!-- releaseit:v1.1.0@sha2 -->\n## v1.1.0\n- feature\n",
+		},
+		{
+			name:     "append",
+			params:   FileParams{Mode: FileModeAppend},
+			existing: new("## v1.0.0\n- initial"),
+			want:     "## v1.0.0\n- initial\n## v1.1.0\n- feature\n",
+		},
+		{
+			name:     "prepend after marker",
+			params:   FileParams{Mode: FileModePrependAfterMarker},
+			version:  "v1.1.0",
+			sha:      "sha2",
+			existing: new("# Changelog\n<!-- releaseit -->\n\n<!-- releaseit:v1.0.0@sha1 -->\n## v1.0.0\n- initial\n"),
+			want: "# Changelog\n<!-- releaseit -->\n\n<!-- releaseit:v1.1.0@sha2 -->\n## v1.1.0\n- feature\n\n" +
+				"<!-- releaseit:v1.0.0@sha1 -->\n## v1.0.0\n- initial\n",
+		},
+		{
+			name:     "prepend after custom marker at the end of file",
+			params:   FileParams{Mode: FileModePrependAfterMarker, Marker: "[//]: # (changelog)"},
+			existing: new("# Changelog\n[//]: # (changelog)"),
+			want:     "# Changelog\n[//]: # (changelog)\n\n## v1.1.0\n- feature\n",
+		},
+		{
+			name:   "prepend to new file",
+			params: FileParams{Mode: FileModePrependAfterMarker},
+			want:   "<!-- releaseit -->\n\n## v1.1.0\n- feature\n",
+		},
+		{
+			name:     "marker not found",
+			params:   FileParams{Mode: FileModePrependAfterMarker},
+			existing: new("# Changelog\n"),
+			wantErr:  `marker "<!-- releaseit -->" not found in `,
+		},
+		{
+			name:     "same version",
+			params:   FileParams{Mode: FileModePrependAfterMarker},
+			version:  "v1.1.0",
+			sha:      "sha2",
+			existing: new("<!-- releaseit -->\n\n<!-- releaseit:v1.1.0@sha2 -->\n## v1.1.0\n- other\n"),
+		},
+		{
+			name:     "same notes without version",
+			params:   FileParams{Mode: FileModeAppend},
+			existing: new("## v1.0.0\n- initial\n## v1.1.0\n- feature\n"),
+		},
+	}
+
+	for _, tt := range tbl {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "CHANGELOG.md")
+			if tt.existing != nil {
+				require.NoError(t, os.WriteFile(path, []byte(*tt.existing), 0o600))
+			}
+
+			tt.params.Path = path
+			tt.params.Log = log.Default()
+			svc, err := NewFile(tt.params)
+			require.NoError(t, err)
+
+			rel := Release{Version: tt.version, Text: "## v1.1.0\n- feature\n\n"}
+			if tt.sha != "" {
+				rel.Commits = []git.Commit{{SHA: "sha1"}, {SHA: tt.sha, ParentSHAs: []string{"sha1"}}}
+			}
+
+			err = svc.Send(context.Background(), rel)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+
+				// file must be left untouched
+				b, err := os.ReadFile(path)
+				require.NoError(t, err)
+				assert.Equal(t, *tt.existing, string(b))
+				return
+			}
+			require.NoError(t, err)
+
+			if tt.want == "" {
+				tt.want = *tt.existing
+			}
+
+			b, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(b))
+		})
+	}
+}
+
+func TestFile_Send_MovingVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "CHANGELOG.md")
+	svc, err := NewFile(FileParams{Log: log.Default(), Path: path, Mode: FileModePrependAfterMarker})
+	require.NoError(t, err)
+
+	first := Release{To: "HEAD", Version: "HEAD", Text: "- first", Commits: []git.Commit{
+		{SHA: "sha2", ParentSHAs: []string{"sha1"}},
+		{SHA: "sha1", ParentSHAs: []string{"sha0"}},
+	}}
+	require.NoError(t, svc.Send(context.Background(), first))
+
+	second := Release{To: "HEAD", Version: "HEAD", Text: "- second", Commits: []git.Commit{
+		{SHA: "sha3", ParentSHAs: []string{"sha2"}},
+	}}
+	require.NoError(t, svc.Send(context.Background(), second))
+
+	// the same release must not be written twice
+	require.NoError(t, svc.Send(context.Background(), second))
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "<!-- releaseit -->\n\n<!-- releaseit:HEAD@sha3 -->\n- second\n\n"+
+		"<!-- releaseit:HEAD@sha2 -->\n- first\n", string(b))
+}
+
+func TestFile_Send_MovedTag(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "CHANGELOG.md")
+	svc, err := NewFile(FileParams{Log: log.Default(), Path: path, Mode: FileModePrependAfterMarker})
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte("<!-- releaseit -->\n\n"+
+		"<!-- releaseit:v1.0.0@sha2 -->\n- first\n\n"+
+		"<!-- releaseit:v0.1.0@sha1 -->\n- initial\n"), 0o600))
+
+	// v1.0.0 is re-tagged at the later commit
+	rel := Release{To: "v1.0.0", Version: "v1.0.0", Text: "- first\n- fix", Commits: []git.Commit{
+		{SHA: "sha2", ParentSHAs: []string{"sha1"}},
+		{SHA: "sha3", ParentSHAs: []string{"sha2"}},
+	}}
+	require.NoError(t, svc.Send(context.Background(), rel))
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "<!-- releaseit -->\n\n"+
+		"<!-- releaseit:v1.0.0@sha3 -->\n- first\n- fix\n\n"+
+		"<!-- releaseit:v0.1.0@sha1 -->\n- initial\n", string(b))
+
+	// the last section spans to the end of the file
+	rel = Release{To: "v0.1.0", Version: "v0.1.0", Text: "- initial, amended", Commits: []git.Commit{
+		{SHA: "sha1a", ParentSHAs: []string{"sha0"}},
+	}}
+	require.NoError(t, svc.Send(context.Background(), rel))
+
+	b, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "<!-- releaseit -->\n\n"+
+		"<!-- releaseit:v1.0.0@sha3 -->\n- first\n- fix\n\n"+
+		"<!-- releaseit:v0.1.0@sha1a -->\n- initial, amended\n", string(b))
+}
+
+func TestNewFile(t *testing.T) {
+	_, err := NewFile(FileParams{Path: "CHANGELOG.md", Mode: "unknown"})
+	assert.EqualError(t, err, `unsupported file mode "unknown"`)
+
+	svc, err := NewFile(FileParams{Path: "CHANGELOG.md", Mode: FileModePrependAfterMarker})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultFileMarker, svc.Marker)
+	assert.Equal(t, "file at CHANGELOG.md (prepend-after-marker)", svc.String())
+}
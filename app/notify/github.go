@@ -4,8 +4,11 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"regexp"
+	"strings"
 	"time"
 
+	"github.com/Semior001/releaseit/app/git/ghclient"
 	"github.com/Semior001/releaseit/app/service/eval"
 	"github.com/go-pkgz/requester"
 	"github.com/go-pkgz/requester/middleware"
@@ -25,27 +28,71 @@ type GithubParams struct {
 	Evaluator           *eval.Evaluator
 	Owner               string
 	Name                string
+	BaseURL             string // api url of github enterprise server, api.github.com by default
 	BasicAuthUsername   string
 	BasicAuthPassword   string
+	Token               string // personal access token
+	App                 ghclient.AppParams
 	HTTPClient          http.Client
 	ReleaseNameTmplText string
 	Tag                 string
 	Extras              map[string]string
+
+	// Update specifies what to do with the release, if it already exists for the tag.
+	Update GithubUpdateMode
+	// Draft marks the new release as a draft, the existing release
+	// is left as is, not to publish the draft on update.
+	Draft bool
+	// Prerelease marks the release as a prerelease, if nil - the new release
+	// is marked as a prerelease, when the tag has a semver pre-release suffix,
+	// and the existing one is left as is.
+	Prerelease *bool
+	// MakeLatest specifies whether the release should be set as the latest
+	// one, might be "true", "false" or "legacy", if empty - github decides.
+	MakeLatest string
 }
 
+// GithubUpdateMode specifies how to update the existing release.
+type GithubUpdateMode string
+
+// Update modes of the existing release.
+const (
+	// GithubUpdateNone doesn't update the existing release, github returns an error in such case.
+	GithubUpdateNone GithubUpdateMode = ""
+	// GithubUpdateReplace replaces the body of the existing release with release notes.
+	GithubUpdateReplace GithubUpdateMode = "replace"
+	// GithubUpdateAppend appends release notes to the body of the existing release.
+	GithubUpdateAppend GithubUpdateMode = "append"
+)
+
+// semverPrereleaseRx matches semver versions with pre-release suffix, e.g. v1.0.0-rc.1
+var semverPrereleaseRx = regexp.MustCompile(`^v?\d+\.\d+\.\d+-[0-9A-Za-z.-]+(\+[0-9A-Za-z.-]+)?$`)
+
 // NewGithub makes new instance of Github.
 func NewGithub(params GithubParams) (*Github, error) {
 	svc := &Github{GithubParams: params}
 
 	cl := requester.New(params.HTTPClient)
 
-	if params.BasicAuthUsername != "" && params.BasicAuthPassword != "" {
+	switch {
+	case params.Token != "":
+		cl.Use(middleware.Header("Authorization", "Bearer "+params.Token))
+	case !params.App.Empty():
+		inst, err := ghclient.NewInstallation(params.App, params.Owner, params.Name, params.BaseURL, params.HTTPClient)
+		if err != nil {
+			return nil, fmt.Errorf("prepare github app installation: %w", err)
+		}
+		cl.Use(inst.Middleware)
+	case params.BasicAuthUsername != "" && params.BasicAuthPassword != "":
 		cl.Use(middleware.BasicAuth(params.BasicAuthUsername, params.BasicAuthPassword))
 	}
 
-	svc.cl = gh.NewClient(cl.Client())
+	var err error
+	if svc.cl, err = ghclient.New(cl.Client(), params.BaseURL); err != nil {
+		return nil, err
+	}
 
-	_, _, err := svc.cl.Repositories.Get(context.Background(), svc.Owner, svc.Name)
+	_, _, err = svc.cl.Repositories.Get(context.Background(), svc.Owner, svc.Name)
 	if err != nil {
 		return nil, fmt.Errorf("check connection to github: %w", err)
 	}
@@ -59,8 +106,9 @@ func (g *Github) String() string {
 }
 
 type releaseNameTmplData struct {
-	Extras map[string]string
-	Tag    struct {
+	Release Release
+	Extras  map[string]string
+	Tag     struct {
 		Name    string
 		Message string
 		Author  string
@@ -80,16 +128,19 @@ type releaseNameTmplData struct {
 	}
 }
 
-// Send makes new release on github repository.
-func (g *Github) Send(ctx context.Context, text string) error {
+// Send makes new release on github repository. If the tag is not
+// specified, the version of the release is used as the tag.
+func (g *Github) Send(ctx context.Context, rel Release) error {
+	tagName := lo.Ternary(g.Tag != "", g.Tag, rel.Version)
+
 	// get tag message
-	tag, _, err := g.cl.Git.GetTag(ctx, g.Owner, g.Name, g.Tag)
+	tag, _, err := g.cl.Git.GetTag(ctx, g.Owner, g.Name, tagName)
 	if err != nil {
-		return fmt.Errorf("get tag %s: %w", g.Tag, err)
+		return fmt.Errorf("get tag %s: %w", tagName, err)
 	}
 
-	data := releaseNameTmplData{}
-	data.Tag.Name = g.Tag
+	data := releaseNameTmplData{Release: rel}
+	data.Tag.Name = tagName
 	data.Tag.Message = tag.GetMessage()
 	data.Tag.Author = tag.GetTagger().GetName()
 	data.Tag.Date = tag.GetTagger().GetDate()
@@ -113,15 +164,92 @@ func (g *Github) Send(ctx context.Context, text string) error {
 	if err != nil {
 		return fmt.Errorf("build release name: %w", err)
 	}
-	release := &gh.RepositoryRelease{
-		TagName: tag.Tag,
-		Name:    lo.ToPtr(name),
-		Body:    &text,
+
+	release := githubReleaseRequest{
+		TagName:    tag.Tag,
+		Name:       lo.ToPtr(name),
+		Body:       &rel.Text,
+		Prerelease: g.Prerelease,
+	}
+
+	if g.MakeLatest != "" {
+		release.MakeLatest = lo.ToPtr(g.MakeLatest)
+	}
+
+	path := fmt.Sprintf("repos/%s/%s/releases", g.Owner, g.Name)
+	method := http.MethodPost
+
+	if g.Update != GithubUpdateNone {
+		existing, err := g.findRelease(ctx, tagName)
+		if err != nil {
+			return fmt.Errorf("find release by tag %s: %w", tagName, err)
+		}
+
+		if existing != nil {
+			if g.Update == GithubUpdateAppend && existing.GetBody() != "" {
+				// release notes are already appended, e.g. by the previous run
+				if strings.Contains(existing.GetBody(), strings.TrimSpace(rel.Text)) {
+					return nil
+				}
+				release.Body = lo.ToPtr(existing.GetBody() + "\n\n" + rel.Text)
+			}
+
+			path = fmt.Sprintf("%s/%d", path, existing.GetID())
+			method = http.MethodPatch
+		}
+	}
+
+	if method == http.MethodPost {
+		release.Draft = lo.ToPtr(g.Draft)
+		if release.Prerelease == nil {
+			release.Prerelease = lo.ToPtr(semverPrereleaseRx.MatchString(tagName))
+		}
 	}
 
-	if _, _, err = g.cl.Repositories.CreateRelease(ctx, g.Owner, g.Name, release); err != nil {
+	req, err := g.cl.NewRequest(method, path, release)
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+
+	if _, err = g.cl.Do(ctx, req, nil); err != nil {
 		return fmt.Errorf("github returned error: %w", err)
 	}
 
 	return nil
 }
+
+// findRelease looks for the release with the given tag, including drafts,
+// which are not returned by the endpoint to get the release by tag.
+// Returns nil, if there is no release for the tag.
+func (g *Github) findRelease(ctx context.Context, tagName string) (*gh.RepositoryRelease, error) {
+	opts := &gh.ListOptions{PerPage: 100}
+	for {
+		releases, resp, err := g.cl.Repositories.ListReleases(ctx, g.Owner, g.Name, opts)
+		if err != nil {
+			return nil, fmt.Errorf("list releases: %w", err)
+		}
+
+		for _, release := range releases {
+			if release.GetTagName() == tagName {
+				return release, nil
+			}
+		}
+
+		if resp.NextPage == 0 {
+			return nil, nil
+		}
+
+		opts.Page = resp.NextPage
+	}
+}
+
+// githubReleaseRequest is a request to create or edit a release,
+// it is declared here, as go-github doesn't support make_latest parameter.
+type githubReleaseRequest struct {
+	TagName    *string `json:"tag_name,omitempty"`
+	Name       *string `json:"name,omitempty"`
+	Body       *string `json:"body,omitempty"`
+	Draft      *bool   `json:"draft,omitempty"`
+	Prerelease *bool   `json:"prerelease,omitempty"`
+	MakeLatest *string `json:"make_latest,omitempty"`
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -110,12 +111,146 @@ func TestGithub_Send(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		err = svc.Send(context.Background(), "body")
+		err = svc.Send(context.Background(), Release{Text: "body"})
 		require.NoError(t, err)
 	})
 
 }
 
+func TestGithub_Send_Update(t *testing.T) {
+	tbl := []struct {
+		name       string
+		tag        string
+		params     GithubParams
+		existing   *gh.RepositoryRelease
+		wantMethod string
+		wantPath   string
+		wantBody   map[string]any
+	}{
+		{
+			name:       "release doesn't exist, prerelease detected",
+			tag:        "v1.0.0-rc.1",
+			params:     GithubParams{Update: GithubUpdateReplace, MakeLatest: "false"},
+			wantMethod: http.MethodPost,
+			wantPath:   "/repos/owner/name/releases",
+			wantBody: map[string]any{"tag_name": "v1.0.0-rc.1", "name": "release", "body": "body",
+				"draft": false, "prerelease": true, "make_latest": "false"},
+		},
+		{
+			name:       "release exists, body replaced",
+			tag:        "v1.0.0",
+			params:     GithubParams{Update: GithubUpdateReplace},
+			existing:   &gh.RepositoryRelease{ID: gh.Int64(1), TagName: gh.String("v1.0.0"), Body: gh.String("old notes")},
+			wantMethod: http.MethodPatch,
+			wantPath:   "/repos/owner/name/releases/1",
+			wantBody:   map[string]any{"tag_name": "v1.0.0", "name": "release", "body": "body"},
+		},
+		{
+			name:   "draft release exists, body replaced, draft is kept",
+			tag:    "v1.0.0-rc.1",
+			params: GithubParams{Update: GithubUpdateReplace},
+			existing: &gh.RepositoryRelease{ID: gh.Int64(1), TagName: gh.String("v1.0.0-rc.1"),
+				Body: gh.String("old notes"), Draft: gh.Bool(true)},
+			wantMethod: http.MethodPatch,
+			wantPath:   "/repos/owner/name/releases/1",
+			wantBody:   map[string]any{"tag_name": "v1.0.0-rc.1", "name": "release", "body": "body"},
+		},
+		{
+			name:       "release exists, body appended, prerelease forced",
+			tag:        "v1.0.0",
+			params:     GithubParams{Update: GithubUpdateAppend, Prerelease: lo.ToPtr(true)},
+			existing:   &gh.RepositoryRelease{ID: gh.Int64(1), TagName: gh.String("v1.0.0"), Body: gh.String("old notes")},
+			wantMethod: http.MethodPatch,
+			wantPath:   "/repos/owner/name/releases/1",
+			wantBody: map[string]any{"tag_name": "v1.0.0", "name": "release", "body": "old notes\n\nbody",
+				"prerelease": true},
+		},
+		{
+			name:     "release exists, body already appended",
+			tag:      "v1.0.0",
+			params:   GithubParams{Update: GithubUpdateAppend},
+			existing: &gh.RepositoryRelease{ID: gh.Int64(1), TagName: gh.String("v1.0.0"), Body: gh.String("old notes\n\nbody")},
+		},
+	}
+
+	for _, tt := range tbl {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+
+			var ts *httptest.Server
+			ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path := strings.TrimPrefix(r.URL.Path, "/api/v3")
+				if r.Method == http.MethodGet && path == "/repos/owner/name/releases" {
+					releases := []*gh.RepositoryRelease{{ID: gh.Int64(2), TagName: gh.String("v0.9.0")}}
+					if r.URL.Query().Get("page") == "" {
+						// the release must be looked up on the next page as well
+						w.Header().Set("Link", "<"+ts.URL+"/api/v3/repos/owner/name/releases?page=2>; rel=\"next\"")
+					} else if tt.existing != nil {
+						releases = append(releases, tt.existing)
+					}
+					require.NoError(t, json.NewEncoder(w).Encode(releases))
+					return
+				}
+
+				switch path {
+				case "/repos/owner/name":
+					w.WriteHeader(http.StatusOK)
+				case "/repos/owner/name/git/tags/" + tt.tag:
+					err := json.NewEncoder(w).Encode(&gh.Tag{
+						Tag:    gh.String(tt.tag),
+						Object: &gh.GitObject{SHA: gh.String("sha"), Type: gh.String("tag")},
+					})
+					require.NoError(t, err)
+				case tt.wantPath:
+					assert.Equal(t, tt.wantMethod, r.Method)
+
+					var body map[string]any
+					require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+					assert.Equal(t, tt.wantBody, body)
+
+					called = true
+					_, err := w.Write([]byte(`{}`))
+					require.NoError(t, err)
+				default:
+					t.Fatalf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+			}))
+			defer ts.Close()
+
+			params := tt.params
+			params.Evaluator = &eval.Evaluator{}
+			params.Owner, params.Name = "owner", "name"
+			params.BaseURL = ts.URL + "/"
+			params.Tag = tt.tag
+			params.ReleaseNameTmplText = "release"
+
+			svc, err := NewGithub(params)
+			require.NoError(t, err)
+
+			require.NoError(t, svc.Send(context.Background(), Release{Text: "body"}))
+			assert.Equal(t, tt.wantPath != "", called)
+		})
+	}
+}
+
+func TestNewGithub_Enterprise(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.Equal(t, "/api/v3/repos/owner/name", r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	_, err := NewGithub(GithubParams{
+		Owner:      "owner",
+		Name:       "name",
+		BaseURL:    ts.URL,
+		Token:      "token",
+		HTTPClient: http.Client{Timeout: 5 * time.Second},
+	})
+	require.NoError(t, err)
+}
+
 func TestGithub_String(t *testing.T) {
 	assert.Equal(t, "github on owner/name", (&Github{GithubParams: GithubParams{Name: "name", Owner: "owner"}}).String())
 }
@@ -0,0 +1,172 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Semior001/releaseit/app/service/eval"
+	"github.com/go-pkgz/requester"
+	"github.com/samber/lo"
+	gl "gitlab.com/gitlab-org/api/client-go"
+)
+
+// Gitlab makes a new release on Gitlab on the given version,
+// or updates the existing one.
+type Gitlab struct {
+	GitlabParams
+
+	cl *gl.Client
+}
+
+// GitlabParams describes parameters to initialize gitlab releaser.
+type GitlabParams struct {
+	Evaluator           *eval.Evaluator
+	Token               string
+	BaseURL             string
+	ProjectID           string
+	HTTPClient          http.Client
+	ReleaseNameTmplText string
+	Tag                 string
+	Milestones          []string          // titles of milestones to associate the release with
+	AssetLinks          []GitlabAssetLink // links to attach to the release
+	Extras              map[string]string
+}
+
+// GitlabAssetLink describes a link to the release asset.
+type GitlabAssetLink struct {
+	Name string
+	URL  string
+}
+
+// NewGitlab makes new instance of Gitlab.
+func NewGitlab(params GitlabParams) (*Gitlab, error) {
+	svc := &Gitlab{GitlabParams: params}
+
+	cl := requester.New(params.HTTPClient)
+
+	var err error
+	svc.cl, err = gl.NewClient(
+		params.Token,
+		gl.WithBaseURL(params.BaseURL),
+		gl.WithHTTPClient(cl.Client()),
+		// retries are made by the Retry destination, if configured
+		gl.WithoutRetries(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("initialize gitlab client: %w", err)
+	}
+
+	if _, _, err = svc.cl.Projects.GetProject(svc.ProjectID, &gl.GetProjectOptions{}); err != nil {
+		return nil, fmt.Errorf("check connection to gitlab: %w", err)
+	}
+
+	return svc, nil
+}
+
+// String returns the string representation of the destination.
+func (g *Gitlab) String() string {
+	return fmt.Sprintf("gitlab on %s", g.ProjectID)
+}
+
+// Send makes new release on gitlab project, if the release for the tag
+// already exists, its name, description and milestones are replaced.
+// If the tag is not specified, the version of the release is used as the tag.
+func (g *Gitlab) Send(ctx context.Context, rel Release) error {
+	tagName := lo.Ternary(g.Tag != "", g.Tag, rel.Version)
+
+	tag, _, err := g.cl.Tags.GetTag(g.ProjectID, tagName, gl.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("get tag %s: %w", tagName, err)
+	}
+
+	data := releaseNameTmplData{Release: rel}
+	data.Tag.Name = tagName
+	data.Tag.Message = tag.Message
+	data.Tag.Date = lo.FromPtr(tag.CreatedAt)
+	data.Extras = g.Extras
+
+	if tag.Commit != nil {
+		data.Commit.SHA = tag.Commit.ID
+		data.Commit.Message = tag.Commit.Message
+		data.Commit.Author.Name = tag.Commit.AuthorName
+		data.Commit.Author.Date = lo.FromPtr(tag.Commit.AuthoredDate)
+		data.Commit.Committer.Name = tag.Commit.CommitterName
+		data.Commit.Committer.Date = lo.FromPtr(tag.Commit.CommittedDate)
+	}
+
+	name, err := g.Evaluator.Evaluate(ctx, g.ReleaseNameTmplText, data)
+	if err != nil {
+		return fmt.Errorf("build release name: %w", err)
+	}
+
+	release, _, err := g.cl.Releases.GetRelease(g.ProjectID, tagName, gl.WithContext(ctx))
+	switch {
+	case errors.Is(err, gl.ErrNotFound):
+		return g.create(ctx, tagName, name, rel.Text)
+	case err != nil:
+		return fmt.Errorf("get release %s: %w", tagName, err)
+	}
+
+	return g.update(ctx, release, name, rel.Text)
+}
+
+func (g *Gitlab) create(ctx context.Context, tagName, name, text string) error {
+	opts := &gl.CreateReleaseOptions{
+		Name:        &name,
+		TagName:     &tagName,
+		Description: &text,
+	}
+
+	if len(g.Milestones) > 0 {
+		opts.Milestones = &g.Milestones
+	}
+
+	if len(g.AssetLinks) > 0 {
+		opts.Assets = &gl.ReleaseAssetsOptions{}
+		for _, link := range g.AssetLinks {
+			opts.Assets.Links = append(opts.Assets.Links, &gl.ReleaseAssetLinkOptions{
+				Name: lo.ToPtr(link.Name),
+				URL:  lo.ToPtr(link.URL),
+			})
+		}
+	}
+
+	if _, _, err := g.cl.Releases.CreateRelease(g.ProjectID, opts, gl.WithContext(ctx)); err != nil {
+		return fmt.Errorf("gitlab returned error: %w", err)
+	}
+
+	return nil
+}
+
+func (g *Gitlab) update(ctx context.Context, release *gl.Release, name, text string) error {
+	opts := &gl.UpdateReleaseOptions{Name: &name, Description: &text}
+
+	if len(g.Milestones) > 0 {
+		opts.Milestones = &g.Milestones
+	}
+
+	if _, _, err := g.cl.Releases.UpdateRelease(g.ProjectID, release.TagName, opts, gl.WithContext(ctx)); err != nil {
+		return fmt.Errorf("gitlab returned error: %w", err)
+	}
+
+	// gitlab doesn't allow links with the same name within the release,
+	// so we attach only those, which are not attached yet
+	existing := lo.SliceToMap(release.Assets.Links, func(link *gl.ReleaseLink) (string, struct{}) {
+		return link.Name, struct{}{}
+	})
+
+	for _, link := range g.AssetLinks {
+		if _, ok := existing[link.Name]; ok {
+			continue
+		}
+
+		opts := &gl.CreateReleaseLinkOptions{Name: lo.ToPtr(link.Name), URL: lo.ToPtr(link.URL)}
+		if _, _, err := g.cl.ReleaseLinks.CreateReleaseLink(g.ProjectID, release.TagName, opts, gl.WithContext(ctx)); err != nil {
+			return fmt.Errorf("attach link %s: %w", link.Name, err)
+		}
+	}
+
+	return nil
+}
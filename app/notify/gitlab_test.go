@@ -0,0 +1,184 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Semior001/releaseit/app/service/eval"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const glReleaseNameTmplText = `{{ .Tag.Name }} {{ .Tag.Message }} {{ .Tag.Date }}
+{{ .Commit.SHA }} {{ .Commit.Message }}
+{{ .Commit.Author.Name }} {{ .Commit.Author.Date }}
+{{ .Commit.Committer.Name }} {{ .Commit.Committer.Date }}`
+
+const glExpectedReleaseName = `v1.0.0 message 2020-01-01 00:00:00 +0000 UTC
+sha commit message
+author-name 2020-01-01 00:00:00 +0000 UTC
+committer-name 2020-01-01 00:00:00 +0000 UTC`
+
+func TestGitlab_Send(t *testing.T) {
+	t.Run("release doesn't exist", func(t *testing.T) {
+		created := false
+		svc := newGitlabNotifier(t, func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.URL.Path == "/api/v4/projects/1/releases/v1.0.0" && r.Method == http.MethodGet:
+				w.WriteHeader(http.StatusNotFound)
+			case r.URL.Path == "/api/v4/projects/1/releases" && r.Method == http.MethodPost:
+				var body struct {
+					Name        string   `json:"name"`
+					TagName     string   `json:"tag_name"`
+					Description string   `json:"description"`
+					Milestones  []string `json:"milestones"`
+					Assets      struct {
+						Links []struct {
+							Name string `json:"name"`
+							URL  string `json:"url"`
+						} `json:"links"`
+					} `json:"assets"`
+				}
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+				assert.Equal(t, glExpectedReleaseName, body.Name)
+				assert.Equal(t, "v1.0.0", body.TagName)
+				assert.Equal(t, "body", body.Description)
+				assert.Equal(t, []string{"v1.0"}, body.Milestones)
+				require.Len(t, body.Assets.Links, 1)
+				assert.Equal(t, "binary", body.Assets.Links[0].Name)
+				assert.Equal(t, "https://example.com/binary", body.Assets.Links[0].URL)
+
+				created = true
+				w.WriteHeader(http.StatusCreated)
+				_, err := w.Write([]byte(`{}`))
+				require.NoError(t, err)
+			default:
+				t.Fatalf("unexpected request %s %s", r.Method, r.URL.Path)
+			}
+		})
+
+		require.NoError(t, svc.Send(context.Background(), Release{Text: "body"}))
+		assert.True(t, created)
+	})
+
+	t.Run("release exists", func(t *testing.T) {
+		updated, linked := false, false
+		svc := newGitlabNotifier(t, func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.URL.Path == "/api/v4/projects/1/releases/v1.0.0" && r.Method == http.MethodGet:
+				_, err := w.Write([]byte(`{"tag_name": "v1.0.0", "assets": {"links": [{"name": "source"}]}}`))
+				require.NoError(t, err)
+			case r.URL.Path == "/api/v4/projects/1/releases/v1.0.0" && r.Method == http.MethodPut:
+				var body struct {
+					Name        string   `json:"name"`
+					Description string   `json:"description"`
+					Milestones  []string `json:"milestones"`
+				}
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+				assert.Equal(t, glExpectedReleaseName, body.Name)
+				assert.Equal(t, "body", body.Description)
+				assert.Equal(t, []string{"v1.0"}, body.Milestones)
+
+				updated = true
+				_, err := w.Write([]byte(`{}`))
+				require.NoError(t, err)
+			case r.URL.Path == "/api/v4/projects/1/releases/v1.0.0/assets/links" && r.Method == http.MethodPost:
+				var body struct {
+					Name string `json:"name"`
+					URL  string `json:"url"`
+				}
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				assert.Equal(t, "binary", body.Name)
+				assert.Equal(t, "https://example.com/binary", body.URL)
+
+				linked = true
+				w.WriteHeader(http.StatusCreated)
+				_, err := w.Write([]byte(`{}`))
+				require.NoError(t, err)
+			default:
+				t.Fatalf("unexpected request %s %s", r.Method, r.URL.Path)
+			}
+		})
+		svc.AssetLinks = append(svc.AssetLinks, GitlabAssetLink{Name: "source", URL: "https://example.com/source"})
+		svc.Tag = "" // the version of the release is used as the tag
+
+		require.NoError(t, svc.Send(context.Background(), Release{Version: "v1.0.0", Text: "body"}))
+		assert.True(t, updated)
+		assert.True(t, linked)
+	})
+
+	t.Run("failed request is not retried by the client", func(t *testing.T) {
+		calls := 0
+		svc := newGitlabNotifier(t, func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.URL.Path == "/api/v4/projects/1/releases/v1.0.0" && r.Method == http.MethodGet:
+				w.WriteHeader(http.StatusNotFound)
+			case r.URL.Path == "/api/v4/projects/1/releases" && r.Method == http.MethodPost:
+				calls++
+				w.WriteHeader(http.StatusBadGateway)
+			default:
+				t.Fatalf("unexpected request %s %s", r.Method, r.URL.Path)
+			}
+		})
+
+		require.Error(t, svc.Send(context.Background(), Release{Text: "body"}))
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestGitlab_String(t *testing.T) {
+	assert.Equal(t, "gitlab on 1", (&Gitlab{GitlabParams: GitlabParams{ProjectID: "1"}}).String())
+}
+
+func newGitlabNotifier(t *testing.T, h http.HandlerFunc) *Gitlab {
+	t.Helper()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "token", r.Header.Get("Private-Token"))
+
+		switch r.URL.Path {
+		case "/api/v4/projects/1":
+			_, err := w.Write([]byte(`{"id": 1}`))
+			require.NoError(t, err)
+		case "/api/v4/projects/1/repository/tags/v1.0.0":
+			_, err := w.Write([]byte(`{
+				"name": "v1.0.0",
+				"message": "message",
+				"created_at": "2020-01-01T00:00:00Z",
+				"commit": {
+					"id": "sha",
+					"message": "commit message",
+					"author_name": "author-name",
+					"authored_date": "2020-01-01T00:00:00Z",
+					"committer_name": "committer-name",
+					"committed_date": "2020-01-01T00:00:00Z"
+				}
+			}`))
+			require.NoError(t, err)
+		default:
+			h(w, r)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	svc, err := NewGitlab(GitlabParams{
+		Evaluator:           &eval.Evaluator{},
+		Token:               "token",
+		BaseURL:             ts.URL,
+		ProjectID:           "1",
+		HTTPClient:          http.Client{Timeout: 5 * time.Second},
+		ReleaseNameTmplText: glReleaseNameTmplText,
+		Tag:                 "v1.0.0",
+		Milestones:          []string{"v1.0"},
+		AssetLinks:          []GitlabAssetLink{{Name: "binary", URL: "https://example.com/binary"}},
+	})
+	require.NoError(t, err)
+
+	return svc
+}
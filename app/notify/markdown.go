@@ -0,0 +1,230 @@
+package notify
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	mdHeaderRx = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*$`)
+	mdListRx   = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+	mdBulletRx = regexp.MustCompile(`^(\s*)[-*+]\s+`)
+	mdQuoteRx  = regexp.MustCompile(`^\s*>\s?(.*)$`)
+	mdRuleRx   = regexp.MustCompile(`^\s*([-*_])(\s*([-*_])){2,}\s*$`)
+	mdLinkRx   = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	mdBoldRx   = regexp.MustCompile(`\*\*(.+?)\*\*|__(.+?)__`)
+	mdItalRx   = regexp.MustCompile(`\*([^*\s](?:[^*]*[^*\s])?)\*|(^|[^\w])_([^_\s](?:[^_]*[^_\s])?)_([^\w]|$)`)
+	mdStrikeRx = regexp.MustCompile(`~~(.+?)~~`)
+)
+
+// markdownToHTML renders the subset of markdown, commonly used in release
+// notes, to HTML: headers, paragraphs, lists, blockquotes, code blocks,
+// horizontal rules, emphasis, inline code and links.
+func markdownToHTML(text string) string {
+	r := &mdRenderer{}
+	for _, line := range strings.Split(text, "\n") {
+		r.line(line)
+	}
+	r.closeBlocks()
+	return strings.TrimSpace(r.out.String())
+}
+
+type mdList struct {
+	indent int
+	tag    string
+}
+
+type mdRenderer struct {
+	out       strings.Builder
+	inCode    bool
+	paragraph []string
+	quote     []string
+	lists     []mdList
+}
+
+func (r *mdRenderer) line(line string) {
+	if strings.HasPrefix(strings.TrimSpace(line), "```") {
+		if r.inCode {
+			r.out.WriteString("</code></pre>\n")
+		} else {
+			r.closeBlocks()
+			r.out.WriteString("<pre><code>")
+		}
+		r.inCode = !r.inCode
+		return
+	}
+
+	if r.inCode {
+		r.out.WriteString(html.EscapeString(line) + "\n")
+		return
+	}
+
+	if strings.TrimSpace(line) == "" {
+		r.closeBlocks()
+		return
+	}
+
+	if m := mdListRx.FindStringSubmatch(line); m != nil && !mdRuleRx.MatchString(line) {
+		r.closeParagraph()
+		r.listItem(len(m[1]), m[2], m[3])
+		return
+	}
+
+	// indented lines continue the current list item
+	if len(r.lists) > 0 && strings.HasPrefix(line, " ") {
+		r.out.WriteString(" " + mdInline(strings.TrimSpace(line)))
+		return
+	}
+
+	r.closeLists()
+
+	if m := mdQuoteRx.FindStringSubmatch(line); m != nil {
+		r.closeParagraph()
+		r.quote = append(r.quote, mdInline(m[1]))
+		return
+	}
+	r.closeQuote()
+
+	if mdRuleRx.MatchString(line) {
+		r.closeParagraph()
+		r.out.WriteString("<hr>\n")
+		return
+	}
+
+	if m := mdHeaderRx.FindStringSubmatch(line); m != nil {
+		r.closeParagraph()
+		lvl := string(rune('0' + len(m[1])))
+		r.out.WriteString("<h" + lvl + ">" + mdInline(m[2]) + "</h" + lvl + ">\n")
+		return
+	}
+
+	r.paragraph = append(r.paragraph, mdInline(strings.TrimSpace(line)))
+}
+
+func (r *mdRenderer) listItem(indent int, marker, content string) {
+	tag := "ul"
+	if marker[0] >= '0' && marker[0] <= '9' {
+		tag = "ol"
+	}
+
+	for len(r.lists) > 0 && r.lists[len(r.lists)-1].indent > indent {
+		r.popList()
+	}
+
+	switch {
+	case len(r.lists) > 0 && r.lists[len(r.lists)-1].indent == indent && r.lists[len(r.lists)-1].tag == tag:
+		r.out.WriteString("</li>\n")
+	case len(r.lists) > 0 && r.lists[len(r.lists)-1].indent == indent:
+		// the same level, but the other type of the list
+		r.popList()
+		fallthrough
+	default:
+		r.out.WriteString("<" + tag + ">\n")
+		r.lists = append(r.lists, mdList{indent: indent, tag: tag})
+	}
+
+	r.out.WriteString("<li>" + mdInline(content))
+}
+
+func (r *mdRenderer) popList() {
+	last := r.lists[len(r.lists)-1]
+	r.lists = r.lists[:len(r.lists)-1]
+	r.out.WriteString("</li>\n</" + last.tag + ">\n")
+}
+
+func (r *mdRenderer) closeLists() {
+	for len(r.lists) > 0 {
+		r.popList()
+	}
+}
+
+func (r *mdRenderer) closeParagraph() {
+	if len(r.paragraph) == 0 {
+		return
+	}
+	r.out.WriteString("<p>" + strings.Join(r.paragraph, "\n") + "</p>\n")
+	r.paragraph = nil
+}
+
+func (r *mdRenderer) closeQuote() {
+	if len(r.quote) == 0 {
+		return
+	}
+	r.out.WriteString("<blockquote>" + strings.Join(r.quote, "<br>\n") + "</blockquote>\n")
+	r.quote = nil
+}
+
+func (r *mdRenderer) closeBlocks() {
+	r.closeParagraph()
+	r.closeQuote()
+	r.closeLists()
+}
+
+// mdInline renders inline markdown elements of the text.
+func mdInline(text string) string {
+	var sb strings.Builder
+
+	// odd parts are inline code, they must be left as is
+	parts := strings.Split(text, "`")
+	for i, part := range parts {
+		part = html.EscapeString(part)
+		switch {
+		case i%2 == 1 && i < len(parts)-1:
+			sb.WriteString("<code>" + part + "</code>")
+		case i%2 == 1:
+			// unpaired backtick
+			sb.WriteString("`" + mdEmphasis(part))
+		default:
+			sb.WriteString(mdEmphasis(part))
+		}
+	}
+
+	return sb.String()
+}
+
+func mdEmphasis(s string) string {
+	s = mdLinkRx.ReplaceAllStringFunc(s, mdLink)
+	s = mdBoldRx.ReplaceAllString(s, "<strong>$1$2</strong>")
+	s = mdItalRx.ReplaceAllString(s, "$2<em>$1$3</em>$4")
+	return mdStrikeRx.ReplaceAllString(s, "<del>$1</del>")
+}
+
+// mdLink renders the link, only if it leads to the web page or email address,
+// links with other schemes, e.g. javascript: or data:, are left as plain text.
+func mdLink(s string) string {
+	m := mdLinkRx.FindStringSubmatch(s)
+
+	// the text is already escaped, unescape it back to get the real url
+	u, err := url.Parse(html.UnescapeString(m[2]))
+	if err != nil {
+		return m[1]
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "mailto":
+		return `<a href="` + m[2] + `">` + m[1] + "</a>"
+	default:
+		return m[1]
+	}
+}
+
+// mdOpenFence returns the opening line of the code block, which is
+// not closed by the end of the text, with the line break, so the code
+// block could be continued in the next message.
+func mdOpenFence(text string) string {
+	fence := ""
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "```") {
+			continue
+		}
+		if fence != "" {
+			fence = ""
+			continue
+		}
+		fence = line + "\n"
+	}
+	return fence
+}
@@ -0,0 +1,52 @@
+package notify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkdownToHTML(t *testing.T) {
+	tbl := []struct {
+		name, in, want string
+	}{
+		{name: "header", in: "## Features", want: "<h2>Features</h2>"},
+		{name: "paragraph", in: "some\ntext\n\nanother", want: "<p>some\ntext</p>\n<p>another</p>"},
+		{
+			name: "emphasis",
+			in:   "**bold** *italic* _italic_ snake_case_name ~~strike~~",
+			want: "<p><strong>bold</strong> <em>italic</em> <em>italic</em> snake_case_name <del>strike</del></p>",
+		},
+		{
+			name: "link and code",
+			in:   "see [PR #1](https://example.com/?a=1&b=2) and `**code** <tag>`",
+			want: `<p>see <a href="https://example.com/?a=1&amp;b=2">PR #1</a> and <code>**code** &lt;tag&gt;</code></p>`,
+		},
+		{
+			name: "unsafe links",
+			in:   "[a](javascript:alert%281%29) [b](JavaScript&#58;alert) [c](data:text/html;base64,PHNjcmlwdD4=) [d](/relative)",
+			want: "<p>a b c d</p>",
+		},
+		{
+			name: "mailto link",
+			in:   "[mail](mailto:dev@example.com)",
+			want: `<p><a href="mailto:dev@example.com">mail</a></p>`,
+		},
+		{name: "unpaired backtick", in: "a ` b", want: "<p>a ` b</p>"},
+		{name: "escape", in: "<script>alert(1)</script>", want: "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>"},
+		{
+			name: "nested lists",
+			in:   "- a\n  - b\n    continued\n- c\n1. d",
+			want: "<ul>\n<li>a<ul>\n<li>b continued</li>\n</ul>\n</li>\n<li>c</li>\n</ul>\n<ol>\n<li>d</li>\n</ol>",
+		},
+		{name: "blockquote", in: "> a\n> **b**", want: "<blockquote>a<br>\n<strong>b</strong></blockquote>"},
+		{name: "code block", in: "```go\n**x** < y\n```", want: "<pre><code>**x** &lt; y\n</code></pre>"},
+		{name: "rule", in: "a\n\n---\nb", want: "<p>a</p>\n<hr>\n<p>b</p>"},
+	}
+
+	for _, tt := range tbl {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, markdownToHTML(tt.in))
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-pkgz/requester"
+	"github.com/go-pkgz/requester/middleware"
+)
+
+// Matrix sends messages to the Matrix room via client-server API.
+type Matrix struct {
+	MatrixParams
+	cl *http.Client
+}
+
+// MatrixParams describes parameters to initialize matrix notifier.
+type MatrixParams struct {
+	Log     *log.Logger
+	Client  http.Client
+	BaseURL string // url of the homeserver, e.g. https://matrix.org
+	Token   string // access token of the user, on behalf of which messages are sent
+	RoomID  string
+	// Notice sends release notes as m.notice message, which
+	// is intended for bots and doesn't trigger notifications.
+	Notice bool
+}
+
+// NewMatrix makes a new Matrix notifier.
+func NewMatrix(params MatrixParams) *Matrix {
+	params.BaseURL = strings.TrimSuffix(params.BaseURL, "/")
+
+	return &Matrix{
+		MatrixParams: params,
+		cl: requester.New(params.Client,
+			middleware.Header("Authorization", "Bearer "+params.Token),
+		).Client(),
+	}
+}
+
+// String returns the name of the notifier.
+func (m *Matrix) String() string {
+	return fmt.Sprintf("matrix room %s at: %s", m.RoomID, extractBaseURL(m.BaseURL))
+}
+
+// Send sends a message to the Matrix room.
+func (m *Matrix) Send(ctx context.Context, text string) error {
+	msgType := "m.text"
+	if m.Notice {
+		msgType = "m.notice"
+	}
+
+	b, err := json.Marshal(matrixMsg{
+		MsgType:       msgType,
+		Body:          text,
+		Format:        "org.matrix.custom.html",
+		FormattedBody: markdownToHTML(text),
+	})
+	if err != nil {
+		return fmt.Errorf("marshal body: %w", err)
+	}
+
+	// transaction id is used by the server to deduplicate retried requests,
+	// so it's generated once per message and reused on each attempt
+	txnID := make([]byte, 16)
+	if _, err = rand.Read(txnID); err != nil {
+		return fmt.Errorf("generate transaction id: %w", err)
+	}
+
+	u := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		m.BaseURL, url.PathEscape(m.RoomID), hex.EncodeToString(txnID))
+
+	return retryRequest(ctx, func() error { return m.send(ctx, u, b) })
+}
+
+func (m *Matrix) send(ctx context.Context, u string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.cl.Do(req)
+	if err != nil {
+		return fmt.Errorf("do request: %w", err)
+	}
+	defer func() {
+		if err = resp.Body.Close(); err != nil {
+			m.Log.Printf("[WARN] can't close request body, %s", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		var mErr struct {
+			ErrCode      string `json:"errcode"`
+			Error        string `json:"error"`
+			RetryAfterMS int64  `json:"retry_after_ms"`
+		}
+		if err = json.NewDecoder(io.LimitReader(resp.Body, 1024)).Decode(&mErr); err != nil || mErr.ErrCode == "" {
+			return checkRetryable(resp, fmt.Errorf("unexpected status code: %d", resp.StatusCode))
+		}
+
+		err = checkRetryable(resp, fmt.Errorf("unexpected status code: %d, %s: %s",
+			resp.StatusCode, mErr.ErrCode, mErr.Error))
+
+		// homeserver specifies the time to wait in the response body on M_LIMIT_EXCEEDED
+		var rerr *retryableError
+		if errors.As(err, &rerr) && mErr.RetryAfterMS > 0 {
+			rerr.after = time.Duration(mErr.RetryAfterMS) * time.Millisecond
+		}
+
+		return err
+	}
+
+	var event struct {
+		EventID string `json:"event_id"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&event); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+
+	m.Log.Printf("[INFO] sent message %s to room %s", event.EventID, m.RoomID)
+
+	return nil
+}
+
+type matrixMsg struct {
+	MsgType       string `json:"msgtype"`
+	Body          string `json:"body"`
+	Format        string `json:"format,omitempty"`
+	FormattedBody string `json:"formatted_body,omitempty"`
+}
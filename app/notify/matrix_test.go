@@ -0,0 +1,95 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatrix_Send(t *testing.T) {
+	t.Run("successful", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPut, r.Method)
+			assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			assert.True(t, strings.HasPrefix(r.URL.EscapedPath(),
+				"/_matrix/client/v3/rooms/%21room:example.org/send/m.room.message/"), r.URL.EscapedPath())
+
+			var msg matrixMsg
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+			assert.Equal(t, matrixMsg{
+				MsgType:       "m.notice",
+				Body:          "## v1.0.0\n- **fix**",
+				Format:        "org.matrix.custom.html",
+				FormattedBody: "<h2>v1.0.0</h2>\n<ul>\n<li><strong>fix</strong></li>\n</ul>",
+			}, msg)
+
+			_, err := w.Write([]byte(`{"event_id": "$event"}`))
+			require.NoError(t, err)
+		}))
+		defer ts.Close()
+
+		svc := NewMatrix(MatrixParams{
+			Log:     log.Default(),
+			BaseURL: ts.URL + "/",
+			Token:   "token",
+			RoomID:  "!room:example.org",
+			Notice:  true,
+		})
+		require.NoError(t, svc.Send(context.Background(), "## v1.0.0\n- **fix**"))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			_, err := w.Write([]byte(`{"errcode": "M_FORBIDDEN", "error": "not in room"}`))
+			require.NoError(t, err)
+		}))
+		defer ts.Close()
+
+		svc := NewMatrix(MatrixParams{Log: log.Default(), BaseURL: ts.URL, RoomID: "!room:example.org"})
+		err := svc.Send(context.Background(), "release notes")
+		assert.EqualError(t, err, "unexpected status code: 403, M_FORBIDDEN: not in room")
+	})
+}
+
+func TestMatrix_Send_TxnID(t *testing.T) {
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+
+		// the first attempt fails, so the message is retried
+		if len(paths) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		_, err := w.Write([]byte(`{"event_id": "$event"}`))
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	svc := NewMatrix(MatrixParams{Log: log.Default(), BaseURL: ts.URL, RoomID: "!room:example.org"})
+	err := NewRetry(Text(svc), RetryParams{Log: log.Default(), Attempts: 2, Delay: time.Millisecond}).
+		Send(context.Background(), Release{Text: "release notes"})
+	require.NoError(t, err)
+
+	require.Len(t, paths, 2)
+	assert.Equal(t, paths[0], paths[1], "retried request must have the same transaction id")
+
+	require.NoError(t, svc.Send(context.Background(), "release notes"))
+	require.Len(t, paths, 3)
+	assert.NotEqual(t, paths[0], paths[2], "the same message sent once again must have another transaction id")
+}
+
+func TestMatrix_String(t *testing.T) {
+	svc := NewMatrix(MatrixParams{BaseURL: "https://matrix.example.org/", RoomID: "!room:example.org"})
+	assert.Equal(t, "matrix room !room:example.org at: https://matrix.example.org", svc.String())
+}
@@ -53,7 +53,7 @@ func (m *Mattermost) Send(ctx context.Context, text string) error {
 	}()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return checkRetryable(resp, fmt.Errorf("unexpected status code: %d", resp.StatusCode))
 	}
 
 	return nil
@@ -104,7 +104,7 @@ func (b *MattermostBot) me(ctx context.Context) (userID string, err error) {
 	}()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return "", checkRetryable(resp, fmt.Errorf("unexpected status code: %d", resp.StatusCode))
 	}
 
 	var u struct {
@@ -150,7 +150,7 @@ func (b *MattermostBot) Send(ctx context.Context, text string) error {
 	}()
 
 	if resp.StatusCode != http.StatusCreated {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return checkRetryable(resp, fmt.Errorf("unexpected status code: %d", resp.StatusCode))
 	}
 
 	var m struct {
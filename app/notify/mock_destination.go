@@ -18,7 +18,7 @@ var _ Destination = &DestinationMock{}
 //
 // 		// make and configure a mocked Destination
 // 		mockedDestination := &DestinationMock{
-// 			SendFunc: func(ctx context.Context, text string) error {
+// 			SendFunc: func(ctx context.Context, rel Release) error {
 // 				panic("mock out the Send method")
 // 			},
 // 			StringFunc: func() string {
@@ -32,7 +32,7 @@ var _ Destination = &DestinationMock{}
 // 	}
 type DestinationMock struct {
 	// SendFunc mocks the Send method.
-	SendFunc func(ctx context.Context, text string) error
+	SendFunc func(ctx context.Context, rel Release) error
 
 	// StringFunc mocks the String method.
 	StringFunc func() string
@@ -43,8 +43,8 @@ type DestinationMock struct {
 		Send []struct {
 			// Ctx is the ctx argument value.
 			Ctx context.Context
-			// Text is the text argument value.
-			Text string
+			// Rel is the rel argument value.
+			Rel Release
 		}
 		// String holds details about calls to the String method.
 		String []struct {
@@ -55,33 +55,33 @@ type DestinationMock struct {
 }
 
 // Send calls SendFunc.
-func (mock *DestinationMock) Send(ctx context.Context, text string) error {
+func (mock *DestinationMock) Send(ctx context.Context, rel Release) error {
 	if mock.SendFunc == nil {
 		panic("DestinationMock.SendFunc: method is nil but Destination.Send was just called")
 	}
 	callInfo := struct {
-		Ctx  context.Context
-		Text string
+		Ctx context.Context
+		Rel Release
 	}{
-		Ctx:  ctx,
-		Text: text,
+		Ctx: ctx,
+		Rel: rel,
 	}
 	mock.lockSend.Lock()
 	mock.calls.Send = append(mock.calls.Send, callInfo)
 	mock.lockSend.Unlock()
-	return mock.SendFunc(ctx, text)
+	return mock.SendFunc(ctx, rel)
 }
 
 // SendCalls gets all the calls that were made to Send.
 // Check the length with:
 //     len(mockedDestination.SendCalls())
 func (mock *DestinationMock) SendCalls() []struct {
-	Ctx  context.Context
-	Text string
+	Ctx context.Context
+	Rel Release
 } {
 	var calls []struct {
-		Ctx  context.Context
-		Text string
+		Ctx context.Context
+		Rel Release
 	}
 	mock.lockSend.RLock()
 	calls = mock.calls.Send
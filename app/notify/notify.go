@@ -7,6 +7,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/Semior001/releaseit/app/git"
 	"github.com/hashicorp/go-multierror"
 )
 
@@ -16,11 +17,85 @@ import (
 // Destination defines interface for a given destination service,
 // like telegram, email or stdout.
 type Destination interface {
+	fmt.Stringer
+	// Send the release to the destination.
+	Send(ctx context.Context, rel Release) error
+}
+
+// TextDestination defines interface for a destination service,
+// which needs only the rendered release notes.
+type TextDestination interface {
 	fmt.Stringer
 	// Send the release notes to the destination.
 	Send(ctx context.Context, text string) error
 }
 
+// Text adapts the TextDestination to Destination.
+func Text(dest TextDestination) Destination {
+	return textDestination{dest: dest}
+}
+
+type textDestination struct {
+	dest TextDestination
+}
+
+// String returns the name of the underlying destination.
+func (d textDestination) String() string { return d.dest.String() }
+
+// Send sends the rendered release notes to the underlying destination.
+func (d textDestination) Send(ctx context.Context, rel Release) error {
+	return d.dest.Send(ctx, rel.Text)
+}
+
+// Named marks the destination with the name, by which the release notes,
+// rendered specifically for this destination, are picked.
+func Named(name string, dest Destination) Destination {
+	return namedDestination{name: name, dest: dest}
+}
+
+type namedDestination struct {
+	name string
+	dest Destination
+}
+
+// String returns the name of the underlying destination.
+func (d namedDestination) String() string { return d.dest.String() }
+
+// Send sends the release with the text, rendered for the destination.
+func (d namedDestination) Send(ctx context.Context, rel Release) error {
+	return d.dest.Send(ctx, rel.For(d.name))
+}
+
+// Release describes the release, which notes are sent to destinations.
+type Release struct {
+	From       string // reference to the commit, from which the release is made
+	To         string // reference to the commit, up to which the release is made
+	Version    string // version of the release, evaluated "to" reference, usually a tag
+	Categories []Category
+	PRs        []git.PullRequest // all closed pull requests of the release
+	Commits    []git.Commit      // all commits of the release
+	Text       string            // rendered release notes
+	// Texts are release notes, rendered with templates, assigned
+	// to specific destinations, by names of destinations.
+	Texts map[string]string
+}
+
+// For returns the release with the text, rendered for the destination
+// with the given name, if any.
+func (r Release) For(dest string) Release {
+	if text, ok := r.Texts[dest]; ok {
+		r.Text = text
+	}
+	return r
+}
+
+// Category is a group of pull requests and commits of the release.
+type Category struct {
+	Title   string
+	PRs     []git.PullRequest
+	Commits []git.Commit
+}
+
 // Destinations is an aggregation of notifiers.
 type Destinations []Destination
 
@@ -33,8 +108,8 @@ func (d Destinations) String() string {
 	return fmt.Sprintf("[%s]", strings.Join(dests, ", "))
 }
 
-// Send sends the message to all destinations.
-func (d Destinations) Send(ctx context.Context, text string) error {
+// Send sends the release to all destinations.
+func (d Destinations) Send(ctx context.Context, rel Release) error {
 	wg := &sync.WaitGroup{}
 	wg.Add(len(d))
 
@@ -43,7 +118,7 @@ func (d Destinations) Send(ctx context.Context, text string) error {
 		dest := dest
 		go func() {
 			defer wg.Done()
-			if err := dest.Send(ctx, text); err != nil {
+			if err := dest.Send(ctx, rel); err != nil {
 				errs <- fmt.Errorf("%s: %w", dest, err)
 			}
 		}()
@@ -70,3 +145,52 @@ func extractBaseURL(url string) string {
 
 	return proto[0] + "://" + strings.Split(proto[1], "/")[0]
 }
+
+// splitText splits the text into chunks of at most limit characters,
+// breaking it on line boundaries, if possible.
+func splitText(text string, limit int) []string {
+	return splitTextFunc(text, limit, func(rune) int { return 1 })
+}
+
+// splitTextFunc splits the text into chunks of at most limit units,
+// measuring each character with runeLen, e.g. in UTF-16 code units.
+func splitTextFunc(text string, limit int, runeLen func(rune) int) []string {
+	var (
+		chunks []string
+		cur    strings.Builder
+		curLen int
+	)
+
+	flush := func() {
+		if chunk := strings.TrimRight(cur.String(), "\n"); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		cur.Reset()
+		curLen = 0
+	}
+
+	for _, line := range strings.SplitAfter(text, "\n") {
+		lineLen := 0
+		for _, r := range line {
+			lineLen += runeLen(r)
+		}
+
+		if curLen+lineLen > limit {
+			flush()
+		}
+
+		// the line itself doesn't fit into the chunk, so we have to break it
+		for _, r := range line {
+			n := runeLen(r)
+			if curLen+n > limit {
+				flush()
+			}
+			cur.WriteRune(r)
+			curLen += n
+		}
+	}
+
+	flush()
+
+	return chunks
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"sort"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/go-multierror"
@@ -21,37 +22,37 @@ func TestDestinations_String(t *testing.T) {
 func TestDestinations_Send(t *testing.T) {
 	t.Run("without errors", func(t *testing.T) {
 		mq := &DestinationMock{
-			SendFunc: func(ctx context.Context, text string) error { return nil },
+			SendFunc: func(ctx context.Context, rel Release) error { return nil },
 		}
 		mq1 := &DestinationMock{
-			SendFunc: func(ctx context.Context, text string) error { return nil },
+			SendFunc: func(ctx context.Context, rel Release) error { return nil },
 		}
 
 		dests := Destinations{mq, mq1}
 
-		require.NoError(t, dests.Send(context.Background(), "release notes"))
+		require.NoError(t, dests.Send(context.Background(), Release{Text: "release notes"}))
 		assert.Equal(t, 1, len(mq.SendCalls()))
 		assert.Equal(t, 1, len(mq1.SendCalls()))
 
-		assert.Equal(t, "release notes", mq.SendCalls()[0].Text)
+		assert.Equal(t, "release notes", mq.SendCalls()[0].Rel.Text)
 
-		assert.Equal(t, "release notes", mq1.SendCalls()[0].Text)
+		assert.Equal(t, "release notes", mq1.SendCalls()[0].Rel.Text)
 	})
 
 	t.Run("with errors", func(t *testing.T) {
 		err, err1 := errors.New("err0"), errors.New("err1")
 		mq := &DestinationMock{
-			SendFunc:   func(ctx context.Context, text string) error { return err },
+			SendFunc:   func(ctx context.Context, rel Release) error { return err },
 			StringFunc: func() string { return "mock0" },
 		}
 		mq1 := &DestinationMock{
-			SendFunc:   func(ctx context.Context, text string) error { return err1 },
+			SendFunc:   func(ctx context.Context, rel Release) error { return err1 },
 			StringFunc: func() string { return "mock1" },
 		}
 
 		dests := Destinations{mq, mq1}
 
-		errs := dests.Send(context.Background(), "release notes")
+		errs := dests.Send(context.Background(), Release{Text: "release notes"})
 		var merr *multierror.Error
 		require.ErrorAsf(t, errs, &merr, "expected multierror")
 		assert.Equal(t, 2, len(merr.Errors))
@@ -62,3 +63,52 @@ func TestDestinations_Send(t *testing.T) {
 		assert.ErrorIs(t, merr.Errors[1], err1)
 	})
 }
+
+func TestText(t *testing.T) {
+	buf := &strings.Builder{}
+	dest := Text(&WriterNotifier{Writer: buf, Name: "buf"})
+
+	assert.Equal(t, "writer to buf", dest.String())
+	require.NoError(t, dest.Send(context.Background(), Release{Version: "v1.0.0", Text: "release notes"}))
+	assert.Equal(t, "release notes", buf.String())
+}
+
+func TestNamed(t *testing.T) {
+	mq := &DestinationMock{
+		SendFunc:   func(context.Context, Release) error { return nil },
+		StringFunc: func() string { return "mock" },
+	}
+
+	rel := Release{Text: "full", Texts: map[string]string{"telegram": "short"}}
+
+	dest := Named("telegram", mq)
+	assert.Equal(t, "mock", dest.String())
+	require.NoError(t, dest.Send(context.Background(), rel))
+
+	require.NoError(t, Named("slack", mq).Send(context.Background(), rel))
+
+	require.Len(t, mq.SendCalls(), 2)
+	assert.Equal(t, "short", mq.SendCalls()[0].Rel.Text)
+	assert.Equal(t, "full", mq.SendCalls()[1].Rel.Text)
+}
+
+func TestSplitText(t *testing.T) {
+	tbl := []struct {
+		name  string
+		text  string
+		limit int
+		want  []string
+	}{
+		{name: "fits", text: "line1\nline2", limit: 20, want: []string{"line1\nline2"}},
+		{name: "split on lines", text: "line1\nline2\nline3", limit: 12, want: []string{"line1\nline2", "line3"}},
+		{name: "long line", text: "short\n0123456789abc\nend", limit: 5, want: []string{"short", "01234", "56789", "abc", "end"}},
+		{name: "multibyte", text: "привет\nмир", limit: 7, want: []string{"привет", "мир"}},
+		{name: "empty", text: "", limit: 5, want: nil},
+	}
+
+	for _, tt := range tbl {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, splitText(tt.text, tt.limit))
+		})
+	}
+}
@@ -44,7 +44,7 @@ func (p *Post) Send(ctx context.Context, text string) error {
 	}()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return checkRetryable(resp, fmt.Errorf("unexpected status code: %d", resp.StatusCode))
 	}
 
 	return nil
@@ -0,0 +1,236 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"time"
+
+	gh "github.com/google/go-github/v37/github"
+	gl "gitlab.com/gitlab-org/api/client-go"
+)
+
+// Retry wraps the destination to retry sending the release notes on
+// transient failures, such as 429 or 5xx responses, with exponential
+// backoff. Destinations, which split release notes into several messages,
+// retry each message on their own, not to repeat already sent ones.
+type Retry struct {
+	RetryParams
+	Destination Destination
+}
+
+// RetryParams describes parameters of retries.
+type RetryParams struct {
+	Log      *log.Logger
+	Attempts int           // the number of attempts, including the first one
+	Delay    time.Duration // the delay before the first retry, doubled on each retry
+	MaxDelay time.Duration // the upper bound of the delay, 0 means no limit
+	// Jitter is the fraction of the delay, by which the delay is randomly
+	// reduced, to spread retries of concurrent clients.
+	Jitter float64
+	// Idempotent specifies that sending the same release twice doesn't
+	// produce duplicates, so the requests, which might have reached
+	// the destination, e.g. timed out ones, are retried too.
+	Idempotent bool
+}
+
+// NewRetry wraps the destination with retries.
+func NewRetry(dest Destination, params RetryParams) *Retry {
+	return &Retry{Destination: dest, RetryParams: params}
+}
+
+// String returns the name of the underlying destination.
+func (r *Retry) String() string { return r.Destination.String() }
+
+// Send sends the release to the underlying destination, retrying
+// on transient failures. If the destination specified the time to wait
+// before the next attempt, it takes precedence over the backoff, but
+// it is still limited by MaxDelay. If the github rate limit is reset
+// later than MaxDelay, the error is returned without retries.
+func (r *Retry) Send(ctx context.Context, rel Release) error {
+	ctx = context.WithValue(ctx, retryCtxKey{}, r)
+	return r.do(ctx, func() error { return r.Destination.Send(ctx, rel) })
+}
+
+func (r *Retry) do(ctx context.Context, fn func() error) error {
+	delay := r.Delay
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+
+		after, ok := retryAfter(err)
+		if !ok && r.Idempotent {
+			ok = isNetworkErr(err)
+		}
+		if !ok || attempt >= r.Attempts {
+			return err
+		}
+
+		// retrying before the primary rate limit is reset is doomed to fail
+		var ghRate *gh.RateLimitError
+		if errors.As(err, &ghRate) && r.MaxDelay > 0 && after > r.MaxDelay {
+			return fmt.Errorf("rate limit is reset in %s, which exceeds the max delay %s: %w",
+				after.Round(time.Second), r.MaxDelay, err)
+		}
+
+		wait := after
+		if wait <= 0 {
+			wait = r.backoff(delay)
+			delay *= 2
+		}
+		if r.MaxDelay > 0 && wait > r.MaxDelay {
+			wait = r.MaxDelay
+		}
+
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(wait).After(deadline) {
+			return fmt.Errorf("no time left to retry in %s: %w", wait, err)
+		}
+
+		r.Log.Printf("[WARN] attempt %d/%d to send to %s failed, retrying in %s: %v",
+			attempt, r.Attempts, r.Destination, wait, err)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w, last error: %w", ctx.Err(), err)
+		case <-time.After(wait):
+		}
+	}
+}
+
+type retryCtxKey struct{}
+
+// retryRequest makes a single request of the destination, which sends
+// release notes in several requests, with retries of the wrapping Retry,
+// if any. The failed request is marked as retried, so that the Retry doesn't
+// send the whole release once again.
+func retryRequest(ctx context.Context, fn func() error) error {
+	r, ok := ctx.Value(retryCtxKey{}).(*Retry)
+	if !ok {
+		return fn()
+	}
+
+	if err := r.do(ctx, fn); err != nil {
+		return &retriedError{err: err}
+	}
+
+	return nil
+}
+
+// retriedError marks the error, which was already retried.
+type retriedError struct{ err error }
+
+func (e *retriedError) Error() string { return e.err.Error() }
+func (e *retriedError) Unwrap() error { return e.err }
+
+func (r *Retry) backoff(delay time.Duration) time.Duration {
+	if r.MaxDelay > 0 && delay > r.MaxDelay {
+		delay = r.MaxDelay
+	}
+	return delay - time.Duration(float64(delay)*r.Jitter*rand.Float64()) //nolint:gosec // not for security
+}
+
+// retryableError marks the wrapped error as transient.
+type retryableError struct {
+	err   error
+	after time.Duration // the time to wait before the next attempt, if known
+}
+
+func (e *retryableError) Error() string { return e.err.Error() }
+func (e *retryableError) Unwrap() error { return e.err }
+
+// checkRetryable marks the error, returned due to the unexpected response,
+// as retryable, if the request might succeed later, i.e. the server responded
+// with 429 or 5xx status code. The Retry-After header is respected, if any.
+func checkRetryable(resp *http.Response, err error) error {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < http.StatusInternalServerError {
+		return err
+	}
+
+	rerr := &retryableError{err: err}
+
+	// Retry-After might be either a number of seconds or a date
+	if h := resp.Header.Get("Retry-After"); h != "" {
+		if secs, perr := strconv.Atoi(h); perr == nil {
+			rerr.after = time.Duration(secs) * time.Second
+		} else if t, perr := http.ParseTime(h); perr == nil {
+			rerr.after = time.Until(t)
+		}
+	}
+
+	return rerr
+}
+
+// retryAfter checks whether the error is transient and returns the time
+// to wait before the next attempt, if the destination specified it.
+func retryAfter(err error) (time.Duration, bool) {
+	var (
+		retried   *retriedError
+		rerr      *retryableError
+		ghRate    *gh.RateLimitError
+		ghAbuse   *gh.AbuseRateLimitError
+		ghResp    *gh.ErrorResponse
+		glResp    *gl.ErrorResponse
+		smtpReply *textproto.Error
+	)
+
+	switch {
+	case errors.As(err, &retried):
+		return 0, false
+	case errors.As(err, &rerr):
+		return rerr.after, true
+	case errors.As(err, &ghRate):
+		return time.Until(ghRate.Rate.Reset.Time), true
+	case errors.As(err, &ghAbuse):
+		return ghAbuse.GetRetryAfter(), true
+	case errors.As(err, &ghResp) && ghResp.Response != nil:
+		if errors.As(checkRetryable(ghResp.Response, err), &rerr) {
+			return rerr.after, true
+		}
+	case errors.As(err, &glResp) && glResp.Response != nil:
+		if errors.As(checkRetryable(glResp.Response, err), &rerr) {
+			return rerr.after, true
+		}
+	case errors.As(err, &smtpReply):
+		// 4xx replies of SMTP servers are transient failures
+		return 0, smtpReply.Code >= 400 && smtpReply.Code < 500
+	}
+
+	return 0, isNotSentErr(err)
+}
+
+// isNotSentErr checks whether the request failed before it was sent,
+// i.e. the connection to the destination couldn't be established,
+// so it is safe to retry it without the risk of duplicates.
+func isNotSentErr(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial" && !errors.Is(err, context.Canceled)
+}
+
+// isNetworkErr checks whether the request failed due to the network,
+// i.e. timed out, the connection was refused or closed unexpectedly.
+// The request might have reached the destination in such case.
+func isNetworkErr(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var (
+		netErr net.Error
+		opErr  *net.OpError
+	)
+
+	return errors.As(err, &netErr) && (netErr.Timeout() || errors.As(err, &opErr))
+}
@@ -0,0 +1,285 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	gh "github.com/google/go-github/v37/github"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetry_Send(t *testing.T) {
+	transient := &retryableError{err: errors.New("unexpected status code: 502")}
+
+	tbl := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantErr   string
+	}{
+		{name: "success", errs: []error{nil}, wantCalls: 1},
+		{name: "retried", errs: []error{transient, transient, nil}, wantCalls: 3},
+		{name: "attempts exceeded", errs: []error{transient, transient, transient}, wantCalls: 3,
+			wantErr: "unexpected status code: 502"},
+		{name: "not retryable", errs: []error{errors.New("unexpected status code: 400")}, wantCalls: 1,
+			wantErr: "unexpected status code: 400"},
+	}
+
+	for _, tt := range tbl {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			dest := &DestinationMock{
+				SendFunc: func(_ context.Context, rel Release) error {
+					assert.Equal(t, "text", rel.Text)
+					calls++
+					return tt.errs[calls-1]
+				},
+				StringFunc: func() string { return "mock" },
+			}
+
+			svc := NewRetry(dest, RetryParams{
+				Log:      log.Default(),
+				Attempts: 3,
+				Delay:    time.Millisecond,
+				MaxDelay: 2 * time.Millisecond,
+				Jitter:   0.5,
+			})
+			assert.Equal(t, "mock", svc.String())
+
+			err := svc.Send(context.Background(), Release{Text: "text"})
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Len(t, dest.SendCalls(), tt.wantCalls)
+		})
+	}
+}
+
+func TestRetry_Send_RetryAfter(t *testing.T) {
+	t.Run("waits the specified time", func(t *testing.T) {
+		calls := 0
+		dest := &DestinationMock{
+			SendFunc: func(context.Context, Release) error {
+				if calls++; calls == 1 {
+					return &retryableError{err: errors.New("too many requests"), after: 50 * time.Millisecond}
+				}
+				return nil
+			},
+			StringFunc: func() string { return "mock" },
+		}
+
+		st := time.Now()
+		err := NewRetry(dest, RetryParams{Log: log.Default(), Attempts: 2, Delay: time.Millisecond}).
+			Send(context.Background(), Release{Text: "text"})
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(st), 50*time.Millisecond)
+	})
+
+	t.Run("limited by max delay", func(t *testing.T) {
+		calls := 0
+		dest := &DestinationMock{
+			SendFunc: func(context.Context, Release) error {
+				if calls++; calls == 1 {
+					return &retryableError{err: errors.New("too many requests"), after: time.Hour}
+				}
+				return nil
+			},
+			StringFunc: func() string { return "mock" },
+		}
+
+		st := time.Now()
+		err := NewRetry(dest, RetryParams{Log: log.Default(), Attempts: 2, MaxDelay: 10 * time.Millisecond}).
+			Send(context.Background(), Release{Text: "text"})
+		require.NoError(t, err)
+		assert.Less(t, time.Since(st), time.Second)
+		assert.Len(t, dest.SendCalls(), 2)
+	})
+
+	t.Run("github rate limit reset exceeds max delay", func(t *testing.T) {
+		dest := &DestinationMock{
+			SendFunc: func(context.Context, Release) error {
+				return &gh.RateLimitError{
+					Rate:     gh.Rate{Reset: gh.Timestamp{Time: time.Now().Add(time.Hour)}},
+					Response: &http.Response{Request: &http.Request{Method: http.MethodPost, URL: &url.URL{}}},
+					Message:  "API rate limit exceeded",
+				}
+			},
+			StringFunc: func() string { return "mock" },
+		}
+
+		err := NewRetry(dest, RetryParams{Log: log.Default(), Attempts: 2, MaxDelay: time.Minute}).
+			Send(context.Background(), Release{Text: "text"})
+		assert.ErrorContains(t, err, "rate limit is reset in 1h0m0s, which exceeds the max delay 1m0s")
+		assert.Len(t, dest.SendCalls(), 1)
+	})
+
+	t.Run("no time left", func(t *testing.T) {
+		dest := &DestinationMock{
+			SendFunc: func(context.Context, Release) error {
+				return &retryableError{err: errors.New("too many requests"), after: time.Minute}
+			},
+			StringFunc: func() string { return "mock" },
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		err := NewRetry(dest, RetryParams{Log: log.Default(), Attempts: 2}).Send(ctx, Release{Text: "text"})
+		assert.EqualError(t, err, "no time left to retry in 1m0s: too many requests")
+		assert.Len(t, dest.SendCalls(), 1)
+	})
+}
+
+func TestRetry_Send_Idempotent(t *testing.T) {
+	timeout := &url.Error{Op: "Post", URL: "http://example.com", Err: context.DeadlineExceeded}
+
+	for _, idempotent := range []bool{false, true} {
+		t.Run(fmt.Sprintf("idempotent=%t", idempotent), func(t *testing.T) {
+			dest := &DestinationMock{
+				SendFunc:   func(context.Context, Release) error { return timeout },
+				StringFunc: func() string { return "mock" },
+			}
+
+			err := NewRetry(dest, RetryParams{Log: log.Default(), Attempts: 3, Delay: time.Millisecond, Idempotent: idempotent}).
+				Send(context.Background(), Release{Text: "text"})
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+			assert.Len(t, dest.SendCalls(), lo.Ternary(idempotent, 3, 1), "timed out request might have been sent")
+		})
+	}
+}
+
+func TestRetry_Send_Parts(t *testing.T) {
+	var descriptions []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg discordMsg
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+		descriptions = append(descriptions, msg.Embeds[0].Description[:1])
+
+		// the second part fails once
+		if len(descriptions) == 2 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	dest := Text(NewDiscord(DiscordParams{Log: log.Default(), URL: ts.URL}))
+	err := NewRetry(dest, RetryParams{Log: log.Default(), Attempts: 3, Delay: time.Millisecond}).
+		Send(context.Background(), Release{Text: strings.Repeat("a", 3000) + "\n" + strings.Repeat("b", 3000)})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"a", "b", "b"}, descriptions, "only the failed part must be resent")
+
+	t.Run("attempts exceeded", func(t *testing.T) {
+		calls := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer ts.Close()
+
+		dest := Text(NewDiscord(DiscordParams{Log: log.Default(), URL: ts.URL}))
+		err := NewRetry(dest, RetryParams{Log: log.Default(), Attempts: 3, Delay: time.Millisecond}).
+			Send(context.Background(), Release{Text: "text"})
+		require.Error(t, err)
+		assert.Equal(t, 3, calls, "the part must not be retried once again by the whole release")
+	})
+}
+
+func TestRetryAfter(t *testing.T) {
+	resp := func(code int, retryAfter string) *http.Response {
+		r := &http.Response{StatusCode: code, Header: http.Header{}}
+		if retryAfter != "" {
+			r.Header.Set("Retry-After", retryAfter)
+		}
+		return r
+	}
+
+	tbl := []struct {
+		name      string
+		err       error
+		wantAfter time.Duration
+		wantOk    bool
+	}{
+		{name: "plain error", err: errors.New("some error")},
+		{name: "bad request", err: checkRetryable(resp(http.StatusBadRequest, ""), errors.New("bad"))},
+		{name: "server error", err: checkRetryable(resp(http.StatusBadGateway, ""), errors.New("bad")), wantOk: true},
+		{
+			name:      "too many requests, seconds",
+			err:       checkRetryable(resp(http.StatusTooManyRequests, "5"), errors.New("bad")),
+			wantAfter: 5 * time.Second,
+			wantOk:    true,
+		},
+		{
+			name:   "wrapped",
+			err:    fmt.Errorf("send part 1/2: %w", checkRetryable(resp(http.StatusServiceUnavailable, ""), errors.New("bad"))),
+			wantOk: true,
+		},
+		{
+			name:      "github abuse rate limit",
+			err:       &gh.AbuseRateLimitError{RetryAfter: new(time.Minute)},
+			wantAfter: time.Minute,
+			wantOk:    true,
+		},
+		{
+			name:   "github server error",
+			err:    &gh.ErrorResponse{Response: resp(http.StatusInternalServerError, "")},
+			wantOk: true,
+		},
+		{name: "github not found", err: &gh.ErrorResponse{Response: resp(http.StatusNotFound, "")}},
+		{name: "smtp transient", err: &textproto.Error{Code: 421, Msg: "try again later"}, wantOk: true},
+		{name: "smtp permanent", err: &textproto.Error{Code: 550, Msg: "no such user"}},
+		{
+			name: "connection refused",
+			err: fmt.Errorf("do request: %w", &url.Error{Op: "Post", URL: "http://example.com",
+				Err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}}),
+			wantOk: true,
+		},
+		{
+			name: "timeout, might be sent",
+			err:  fmt.Errorf("do request: %w", &url.Error{Op: "Post", URL: "http://example.com", Err: context.DeadlineExceeded}),
+		},
+		{name: "connection closed, might be sent", err: fmt.Errorf("do request: %w", io.ErrUnexpectedEOF)},
+		{
+			name: "connection reset, might be sent",
+			err: &url.Error{Op: "Post", URL: "http://example.com",
+				Err: &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}},
+		},
+		{name: "canceled", err: &url.Error{Op: "Post", URL: "http://example.com", Err: context.Canceled}},
+		{name: "bad certificate", err: &url.Error{Op: "Post", URL: "http://example.com", Err: errors.New("x509: unknown authority")}},
+	}
+
+	for _, tt := range tbl {
+		t.Run(tt.name, func(t *testing.T) {
+			after, ok := retryAfter(tt.err)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.wantAfter, after)
+		})
+	}
+
+	t.Run("http date", func(t *testing.T) {
+		err := checkRetryable(resp(http.StatusTooManyRequests,
+			time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)), errors.New("bad"))
+		after, ok := retryAfter(err)
+		assert.True(t, ok)
+		assert.InDelta(t, time.Hour, after, float64(2*time.Second))
+	})
+}
@@ -0,0 +1,268 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/go-pkgz/requester"
+	"github.com/go-pkgz/requester/middleware"
+)
+
+// Slack sends messages to Slack via incoming webhook.
+type Slack struct {
+	log *log.Logger
+	cl  *http.Client
+	url string
+}
+
+// NewSlack makes a new Slack notifier.
+func NewSlack(lg *log.Logger, cl http.Client, url string) *Slack {
+	return &Slack{cl: &cl, url: url, log: lg}
+}
+
+// String returns the name of the notifier.
+func (s *Slack) String() string {
+	return fmt.Sprintf("slack hook at: %s", extractBaseURL(s.url))
+}
+
+// Send sends a message to Slack.
+func (s *Slack) Send(ctx context.Context, text string) error {
+	b, err := json.Marshal(map[string]string{"text": slackMrkdwn(text)})
+	if err != nil {
+		return fmt.Errorf("marshal body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.cl.Do(req)
+	if err != nil {
+		return fmt.Errorf("do request: %w", err)
+	}
+	defer func() {
+		if err = resp.Body.Close(); err != nil {
+			s.log.Printf("[WARN] can't close request body, %s", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		// slack responds with the error code in plain text, e.g. "invalid_payload"
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return checkRetryable(resp, fmt.Errorf("unexpected status code: %d, message: %q", resp.StatusCode, msg))
+	}
+
+	return nil
+}
+
+// SlackBot sends messages to Slack channel via bot with chat.postMessage method.
+type SlackBot struct {
+	SlackBotParams
+	cl     *http.Client
+	userID string
+}
+
+// SlackBotParams describes parameters to initialize slack bot notifier.
+type SlackBotParams struct {
+	Log       *log.Logger
+	Client    http.Client
+	BaseURL   string // https://slack.com/api by default
+	Token     string
+	ChannelID string
+	// ThreadTS is the timestamp of the parent message, if set,
+	// release notes are sent as a reply in its thread.
+	ThreadTS string
+}
+
+// NewSlackBot makes a new SlackBot notifier.
+func NewSlackBot(params SlackBotParams) (bot *SlackBot, err error) {
+	if params.BaseURL == "" {
+		params.BaseURL = "https://slack.com/api"
+	}
+	params.BaseURL = strings.TrimSuffix(params.BaseURL, "/")
+
+	bot = &SlackBot{
+		SlackBotParams: params,
+		cl: requester.New(params.Client,
+			middleware.Header("Authorization", "Bearer "+params.Token),
+		).Client(),
+	}
+
+	var resp struct {
+		UserID string `json:"user_id"`
+	}
+
+	if err = bot.call(context.Background(), "auth.test", nil, &resp); err != nil {
+		return nil, fmt.Errorf("get bot's userID: %w", err)
+	}
+
+	bot.userID = resp.UserID
+
+	return bot, nil
+}
+
+// String returns the name of the notifier.
+func (b *SlackBot) String() string {
+	return fmt.Sprintf("slack bot %.4s... channel: %.4s... at: %s",
+		b.userID, b.ChannelID, extractBaseURL(b.BaseURL))
+}
+
+// Send sends a message to Slack.
+func (b *SlackBot) Send(ctx context.Context, text string) error {
+	req := map[string]any{
+		"channel": b.ChannelID,
+		"text":    slackMrkdwn(text),
+		"mrkdwn":  true,
+	}
+
+	if b.ThreadTS != "" {
+		req["thread_ts"] = b.ThreadTS
+	}
+
+	var resp struct {
+		TS string `json:"ts"`
+	}
+
+	if err := b.call(ctx, "chat.postMessage", req, &resp); err != nil {
+		return err
+	}
+
+	b.Log.Printf("[INFO] sent message %s to channel %s", resp.TS, b.ChannelID)
+
+	return nil
+}
+
+// call calls the slack web API method, slack responds with 200 even on
+// errors, so the "ok" field of the response is checked.
+func (b *SlackBot) call(ctx context.Context, method string, body, dst any) error {
+	bts, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("marshal body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.BaseURL+"/"+method, bytes.NewReader(bts))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	resp, err := b.cl.Do(req)
+	if err != nil {
+		return fmt.Errorf("do request: %w", err)
+	}
+	defer func() {
+		if err = resp.Body.Close(); err != nil {
+			b.Log.Printf("[WARN] can't close request body, %s", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return checkRetryable(resp, fmt.Errorf("unexpected status code: %d", resp.StatusCode))
+	}
+
+	bts, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+
+	var status struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+
+	if err = json.Unmarshal(bts, &status); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+
+	if !status.OK {
+		return fmt.Errorf("slack returned error: %s", status.Error)
+	}
+
+	if err = json.Unmarshal(bts, dst); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+
+	return nil
+}
+
+// slackItalicRx matches only asterisks, as underscores are already
+// the italic marks in mrkdwn and are left as is.
+var slackItalicRx = regexp.MustCompile(`\*([^*\s](?:[^*]*[^*\s])?)\*`)
+
+// slackMrkdwn converts markdown text to the slack's mrkdwn format.
+func slackMrkdwn(text string) string {
+	lines := strings.Split(text, "\n")
+
+	inCode := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+			lines[i] = slackEscape(line)
+			continue
+		}
+
+		if inCode {
+			lines[i] = slackEscape(line)
+			continue
+		}
+
+		lines[i] = slackMrkdwnLine(line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func slackMrkdwnLine(line string) string {
+	// blockquotes are the same in mrkdwn, but the mark must not be escaped
+	quote := ""
+	if strings.HasPrefix(line, ">") {
+		quote, line = ">", line[1:]
+	}
+
+	// slack doesn't support headers, make them bold instead
+	header := false
+	if m := mdHeaderRx.FindStringSubmatch(line); m != nil {
+		line, header = strings.ReplaceAll(m[2], "**", ""), true
+	}
+
+	line = mdBulletRx.ReplaceAllString(line, "${1}• ")
+
+	// odd parts are inline code, they must be left as is
+	parts := strings.Split(line, "`")
+	for i := range parts {
+		parts[i] = slackEscape(parts[i])
+		if i%2 == 0 {
+			parts[i] = slackMrkdwnInline(parts[i])
+		}
+	}
+	line = strings.Join(parts, "`")
+
+	if header && line != "" {
+		line = "*" + line + "*"
+	}
+
+	return quote + line
+}
+
+func slackMrkdwnInline(s string) string {
+	s = mdLinkRx.ReplaceAllString(s, "<$2|$1>")
+	// replace bold marks with placeholder to not treat them as italic
+	s = mdBoldRx.ReplaceAllString(s, "\x00$1$2\x00")
+	s = slackItalicRx.ReplaceAllString(s, "_${1}_")
+	s = mdStrikeRx.ReplaceAllString(s, "~$1~")
+	return strings.ReplaceAll(s, "\x00", "*")
+}
+
+// slackEscape escapes control characters of slack messages.
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
@@ -0,0 +1,139 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlack_Send(t *testing.T) {
+	t.Run("successful", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/services/T/B/X", r.URL.Path)
+			assert.Equal(t, http.MethodPost, r.Method)
+
+			var body struct {
+				Text string `json:"text"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "*release notes*", body.Text)
+
+			_, err := w.Write([]byte("ok"))
+			require.NoError(t, err)
+		}))
+		defer ts.Close()
+
+		svc := NewSlack(log.Default(), *http.DefaultClient, ts.URL+"/services/T/B/X")
+		assert.NoError(t, svc.Send(context.Background(), "**release notes**"))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, err := w.Write([]byte("invalid_payload"))
+			require.NoError(t, err)
+		}))
+		defer ts.Close()
+
+		svc := NewSlack(log.Default(), *http.DefaultClient, ts.URL)
+		err := svc.Send(context.Background(), "release notes")
+		assert.EqualError(t, err, `unexpected status code: 400, message: "invalid_payload"`)
+	})
+}
+
+func TestSlack_String(t *testing.T) {
+	svc := NewSlack(log.Default(), *http.DefaultClient, "https://hooks.slack.com/services/T/B/X")
+	assert.Equal(t, "slack hook at: https://hooks.slack.com", svc.String())
+}
+
+func TestSlackBot_Send(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.Equal(t, http.MethodPost, r.Method)
+
+		switch r.URL.Path {
+		case "/api/auth.test":
+			_, err := w.Write([]byte(`{"ok": true, "user_id": "U123"}`))
+			require.NoError(t, err)
+		case "/api/chat.postMessage":
+			var body map[string]any
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, map[string]any{
+				"channel":   "C123",
+				"text":      "*Release* <https://example.com|link>",
+				"mrkdwn":    true,
+				"thread_ts": "1234.5678",
+			}, body)
+
+			_, err := w.Write([]byte(`{"ok": true, "ts": "1234.5679"}`))
+			require.NoError(t, err)
+		default:
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	svc, err := NewSlackBot(SlackBotParams{
+		Log:       log.Default(),
+		Client:    *http.DefaultClient,
+		BaseURL:   ts.URL + "/api/",
+		Token:     "token",
+		ChannelID: "C123",
+		ThreadTS:  "1234.5678",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "U123", svc.userID)
+	assert.Equal(t, "slack bot U123... channel: C123... at: "+ts.URL, svc.String())
+
+	require.NoError(t, svc.Send(context.Background(), "**Release** [link](https://example.com)"))
+}
+
+func TestSlackBot_Send_Error(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/auth.test":
+			_, err := w.Write([]byte(`{"ok": true, "user_id": "U123"}`))
+			require.NoError(t, err)
+		default:
+			_, err := w.Write([]byte(`{"ok": false, "error": "channel_not_found"}`))
+			require.NoError(t, err)
+		}
+	}))
+	defer ts.Close()
+
+	svc, err := NewSlackBot(SlackBotParams{Log: log.Default(), BaseURL: ts.URL, Token: "token", ChannelID: "C123"})
+	require.NoError(t, err)
+
+	err = svc.Send(context.Background(), "release notes")
+	assert.EqualError(t, err, "slack returned error: channel_not_found")
+}
+
+func TestSlackMrkdwn(t *testing.T) {
+	tbl := []struct {
+		name, in, want string
+	}{
+		{name: "bold", in: "**bold** and __bold__", want: "*bold* and *bold*"},
+		{name: "italic", in: "*italic* and _italic_", want: "_italic_ and _italic_"},
+		{name: "bold and italic", in: "**bold** *italic*", want: "*bold* _italic_"},
+		{name: "strikethrough", in: "~~strike~~", want: "~strike~"},
+		{name: "link", in: "see [PR #1](https://example.com/pr/1)", want: "see <https://example.com/pr/1|PR #1>"},
+		{name: "header", in: "## Features **new**", want: "*Features new*"},
+		{name: "list", in: "- item\n  * nested", want: "• item\n  • nested"},
+		{name: "escape", in: "a < b && c > d", want: "a &lt; b &amp;&amp; c &gt; d"},
+		{name: "blockquote", in: "> quote **bold**", want: "> quote *bold*"},
+		{name: "inline code", in: "`**not bold**` **bold**", want: "`**not bold**` *bold*"},
+		{name: "code block", in: "```\n**not bold**\n- not list\n```\n- list", want: "```\n**not bold**\n- not list\n```\n• list"},
+	}
+
+	for _, tt := range tbl {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, slackMrkdwn(tt.in))
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// Teams sends messages to Microsoft Teams via incoming webhook or
+// Workflows URL, wrapping the release notes in an Adaptive Card.
+type Teams struct {
+	TeamsParams
+}
+
+// TeamsParams describes parameters to initialize teams notifier.
+type TeamsParams struct {
+	Log     *log.Logger
+	Client  http.Client
+	URL     string
+	Title   string        // title of the card, omitted if empty
+	Actions []TeamsAction // buttons to show under the release notes
+}
+
+// TeamsAction describes a button of the card, which opens the URL.
+type TeamsAction struct {
+	Title string
+	URL   string
+}
+
+// NewTeams makes a new Teams notifier.
+func NewTeams(params TeamsParams) *Teams {
+	return &Teams{TeamsParams: params}
+}
+
+// String returns the name of the notifier.
+func (t *Teams) String() string {
+	return fmt.Sprintf("teams at: %s", extractBaseURL(t.URL))
+}
+
+// Send sends a message to Teams.
+func (t *Teams) Send(ctx context.Context, text string) error {
+	b, err := json.Marshal(t.message(text))
+	if err != nil {
+		return fmt.Errorf("marshal body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.URL, bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("do request: %w", err)
+	}
+	defer func() {
+		if err = resp.Body.Close(); err != nil {
+			t.Log.Printf("[WARN] can't close request body, %s", err)
+		}
+	}()
+
+	// incoming webhooks respond with 200, while workflows respond with 202
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return checkRetryable(resp, fmt.Errorf("unexpected status code: %d, message: %q", resp.StatusCode, msg))
+	}
+
+	return nil
+}
+
+func (t *Teams) message(text string) map[string]any {
+	var body []map[string]any
+	if t.Title != "" {
+		body = append(body, map[string]any{
+			"type":   "TextBlock",
+			"text":   t.Title,
+			"weight": "Bolder",
+			"size":   "Medium",
+			"wrap":   true,
+		})
+	}
+
+	body = append(body, map[string]any{
+		"type": "TextBlock",
+		"text": teamsText(text),
+		"wrap": true,
+	})
+
+	card := map[string]any{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"body":    body,
+		"msteams": map[string]any{"width": "Full"},
+	}
+
+	if len(t.Actions) > 0 {
+		actions := make([]map[string]any, len(t.Actions))
+		for i, action := range t.Actions {
+			actions[i] = map[string]any{"type": "Action.OpenUrl", "title": action.Title, "url": action.URL}
+		}
+		card["actions"] = actions
+	}
+
+	return map[string]any{
+		"type": "message",
+		"attachments": []map[string]any{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content":     card,
+		}},
+	}
+}
+
+// teamsText makes headers bold, as they are not supported by adaptive cards.
+func teamsText(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if m := mdHeaderRx.FindStringSubmatch(line); m != nil {
+			lines[i] = "**" + m[2] + "**"
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,100 @@
+package notify
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTeams_Send(t *testing.T) {
+	t.Run("card with title and actions", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/workflows/123", r.URL.Path)
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+			b, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			assert.JSONEq(t, `{
+				"type": "message",
+				"attachments": [{
+					"contentType": "application/vnd.microsoft.card.adaptive",
+					"content": {
+						"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+						"type": "AdaptiveCard",
+						"version": "1.4",
+						"body": [
+							{"type": "TextBlock", "text": "Release v1.0.0", "weight": "Bolder", "size": "Medium", "wrap": true},
+							{"type": "TextBlock", "text": "**Features**\n- feature", "wrap": true}
+						],
+						"actions": [
+							{"type": "Action.OpenUrl", "title": "Compare", "url": "https://example.com/compare/v0.9.0...v1.0.0"}
+						],
+						"msteams": {"width": "Full"}
+					}
+				}]
+			}`, string(b))
+
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer ts.Close()
+
+		svc := NewTeams(TeamsParams{
+			Log:     log.Default(),
+			URL:     ts.URL + "/workflows/123",
+			Title:   "Release v1.0.0",
+			Actions: []TeamsAction{{Title: "Compare", URL: "https://example.com/compare/v0.9.0...v1.0.0"}},
+		})
+		assert.NoError(t, svc.Send(context.Background(), "## Features\n- feature"))
+	})
+
+	t.Run("plain card", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			assert.JSONEq(t, `{
+				"type": "message",
+				"attachments": [{
+					"contentType": "application/vnd.microsoft.card.adaptive",
+					"content": {
+						"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+						"type": "AdaptiveCard",
+						"version": "1.4",
+						"body": [{"type": "TextBlock", "text": "release notes", "wrap": true}],
+						"msteams": {"width": "Full"}
+					}
+				}]
+			}`, string(b))
+
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer ts.Close()
+
+		svc := NewTeams(TeamsParams{Log: log.Default(), URL: ts.URL})
+		assert.NoError(t, svc.Send(context.Background(), "release notes"))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, err := w.Write([]byte("Bad payload"))
+			require.NoError(t, err)
+		}))
+		defer ts.Close()
+
+		svc := NewTeams(TeamsParams{Log: log.Default(), URL: ts.URL})
+		err := svc.Send(context.Background(), "release notes")
+		assert.EqualError(t, err, `unexpected status code: 400, message: "Bad payload"`)
+	})
+}
+
+func TestTeams_String(t *testing.T) {
+	svc := NewTeams(TeamsParams{URL: "https://example.webhook.office.com/webhookb2/123"})
+	assert.Equal(t, "teams at: https://example.webhook.office.com", svc.String())
+}
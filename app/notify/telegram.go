@@ -4,10 +4,15 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
 )
 
 // Telegram implements Destination to send changelogs to specified
@@ -23,9 +28,17 @@ type TelegramParams struct {
 	Client                http.Client
 	Token                 string
 	DisableWebPagePreview bool
+	// MessageThreadID is the id of the forum topic, if set,
+	// release notes are sent to this topic.
+	MessageThreadID int
 }
 
-const telegramAPIBaseURL = "https://api.telegram.org/bot"
+const (
+	telegramAPIBaseURL = "https://api.telegram.org/bot"
+	// telegramMessageLimit is the maximum length of the message text
+	// after entities parsing, in UTF-16 code units.
+	telegramMessageLimit = 4096
+)
 
 // NewTelegram makes telegram bot for notifications
 func NewTelegram(params TelegramParams) *Telegram {
@@ -37,27 +50,45 @@ func (t *Telegram) String() string {
 	return fmt.Sprintf("telegram to chatID %s", t.ChatID)
 }
 
-// Send changelog via Telegram.
+// Send changelog via Telegram. Markdown of the release notes is converted
+// to the telegram's MarkdownV2, long release notes are split into several
+// sequential messages.
 func (t *Telegram) Send(ctx context.Context, text string) error {
-	msg, err := json.Marshal(tgMsg{Text: text, ParseMode: "MarkdownV2"})
-	if err != nil {
-		return fmt.Errorf("marshal tg message: %w", err)
+	chatID := t.ChatID
+	if _, err := strconv.ParseInt(chatID, 10, 64); err != nil {
+		chatID = "@" + chatID // if chatID not a number enforce @ prefix
 	}
 
-	if err := t.sendMessage(ctx, msg, t.ChatID); err != nil {
-		return fmt.Errorf("send message: %w", err)
+	// the limit is applied to the text without markup, so it's split
+	// before the conversion
+	chunks := splitTextFunc(text, telegramMessageLimit, utf16.RuneLen)
+
+	fence := "" // opening line of the code block, cut by the previous chunk
+	for idx, chunk := range chunks {
+		chunk = fence + chunk
+		fence = mdOpenFence(chunk)
+
+		msg, err := json.Marshal(tgMsg{
+			ChatID:                chatID,
+			MessageThreadID:       t.MessageThreadID,
+			Text:                  tgMarkdownV2(chunk),
+			ParseMode:             "MarkdownV2",
+			DisableWebPagePreview: t.DisableWebPagePreview,
+		})
+		if err != nil {
+			return fmt.Errorf("marshal tg message: %w", err)
+		}
+
+		if err = retryRequest(ctx, func() error { return t.sendMessage(ctx, msg) }); err != nil {
+			return fmt.Errorf("send message %d/%d: %w", idx+1, len(chunks), err)
+		}
 	}
 
 	return nil
 }
 
-func (t *Telegram) sendMessage(ctx context.Context, msg []byte, chatID string) error {
-	if _, err := strconv.ParseInt(chatID, 10, 64); err != nil {
-		chatID = "@" + chatID // if chatID not a number enforce @ prefix
-	}
-
-	u := fmt.Sprintf("%s%s/sendMessage?chat_id=%s&parse_mode=Markdown&disable_web_page_preview=%t",
-		telegramAPIBaseURL, t.Token, chatID, t.DisableWebPagePreview)
+func (t *Telegram) sendMessage(ctx context.Context, msg []byte) error {
+	u := fmt.Sprintf("%s%s/sendMessage", telegramAPIBaseURL, t.Token)
 	r, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(msg))
 	if err != nil {
 		return fmt.Errorf("make telegram request: %w", err)
@@ -76,11 +107,20 @@ func (t *Telegram) sendMessage(ctx context.Context, msg []byte, chatID string) e
 
 	if resp.StatusCode != http.StatusOK {
 		tgErr := tgError{}
-		if err = json.NewDecoder(resp.Body).Decode(&tgErr); err == nil {
-			return fmt.Errorf("unexpected telegram API status code %d, error: %q",
-				resp.StatusCode, tgErr.Description)
+		if err = json.NewDecoder(resp.Body).Decode(&tgErr); err != nil {
+			return checkRetryable(resp, fmt.Errorf("unexpected telegram API status code %d", resp.StatusCode))
 		}
-		return fmt.Errorf("unexpected telegram API status code %d", resp.StatusCode)
+
+		err = checkRetryable(resp, fmt.Errorf("unexpected telegram API status code %d, error: %q",
+			resp.StatusCode, tgErr.Description))
+
+		// telegram specifies the time to wait in the response body on flood control
+		var rerr *retryableError
+		if errors.As(err, &rerr) && tgErr.Parameters.RetryAfter > 0 {
+			rerr.after = time.Duration(tgErr.Parameters.RetryAfter) * time.Second
+		}
+
+		return err
 	}
 
 	tgResp := struct {
@@ -100,9 +140,123 @@ func (t *Telegram) sendMessage(ctx context.Context, msg []byte, chatID string) e
 
 type tgError struct {
 	Description string `json:"description"`
+	Parameters  struct {
+		RetryAfter int `json:"retry_after"`
+	} `json:"parameters"`
 }
 
 type tgMsg struct {
-	Text      string `json:"text"`
-	ParseMode string `json:"parse_mode,omitempty"`
+	ChatID                string `json:"chat_id"`
+	MessageThreadID       int    `json:"message_thread_id,omitempty"`
+	Text                  string `json:"text"`
+	ParseMode             string `json:"parse_mode,omitempty"`
+	DisableWebPagePreview bool   `json:"disable_web_page_preview,omitempty"`
+}
+
+var tgInlineRx = regexp.MustCompile("`([^`]+)`" + // code
+	"|" + mdLinkRx.String() +
+	"|" + mdBoldRx.String() +
+	"|" + mdStrikeRx.String() +
+	`|\*([^*\s](?:[^*]*[^*\s])?)\*|\b_([^_\s](?:[^_]*[^_\s])?)_\b`) // italic
+
+// tgMarkdownV2 converts markdown text to the telegram's MarkdownV2,
+// escaping all reserved characters outside of formatting entities.
+func tgMarkdownV2(text string) string {
+	lines := strings.Split(text, "\n")
+
+	inCode := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+			lines[i] = strings.TrimSpace(line)
+			continue
+		}
+
+		if inCode {
+			lines[i] = tgEscapeCode(line)
+			continue
+		}
+
+		lines[i] = tgMarkdownV2Line(line)
+	}
+
+	// code block might be cut by splitting the message
+	if inCode {
+		lines = append(lines, "```")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func tgMarkdownV2Line(line string) string {
+	// blockquotes are the same in MarkdownV2, but the mark must not be escaped
+	quote := ""
+	if strings.HasPrefix(line, ">") {
+		quote, line = ">", strings.TrimPrefix(line[1:], " ")
+	}
+
+	// telegram doesn't support headers, make them bold instead
+	if m := mdHeaderRx.FindStringSubmatch(line); m != nil {
+		if content := strings.ReplaceAll(m[2], "**", ""); content != "" {
+			return quote + "*" + tgInline(content) + "*"
+		}
+		return quote
+	}
+
+	line = mdBulletRx.ReplaceAllString(line, "${1}• ")
+
+	return quote + tgInline(line)
+}
+
+// tgInline renders inline markdown elements of the text.
+func tgInline(s string) string {
+	var sb strings.Builder
+
+	for {
+		m := tgInlineRx.FindStringSubmatchIndex(s)
+		if m == nil {
+			sb.WriteString(tgEscape(s))
+			return sb.String()
+		}
+
+		sb.WriteString(tgEscape(s[:m[0]]))
+
+		group := func(n int) string { return s[m[2*n]:m[2*n+1]] }
+		switch {
+		case m[2] >= 0:
+			sb.WriteString("`" + tgEscapeCode(group(1)) + "`")
+		case m[4] >= 0:
+			url := strings.NewReplacer(`\`, `\\`, ")", `\)`).Replace(group(3))
+			sb.WriteString("[" + tgInline(group(2)) + "](" + url + ")")
+		case m[8] >= 0:
+			sb.WriteString("*" + tgInline(group(4)) + "*")
+		case m[10] >= 0:
+			sb.WriteString("*" + tgInline(group(5)) + "*")
+		case m[12] >= 0:
+			sb.WriteString("~" + tgInline(group(6)) + "~")
+		case m[14] >= 0:
+			sb.WriteString("_" + tgInline(group(7)) + "_")
+		case m[16] >= 0:
+			sb.WriteString("_" + tgInline(group(8)) + "_")
+		}
+
+		s = s[m[1]:]
+	}
+}
+
+// tgEscape escapes characters, reserved by MarkdownV2.
+func tgEscape(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune("_*[]()~`>#+-=|{}.!\\", r) {
+			sb.WriteRune('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// tgEscapeCode escapes characters, reserved inside of code entities.
+func tgEscapeCode(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "`", "\\`").Replace(s)
 }
@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -16,22 +18,18 @@ func TestTelegram_String(t *testing.T) {
 }
 
 func TestTelegram_Send(t *testing.T) {
+	var msgs []tgMsg
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "POST", r.Method)
 		assert.Equal(t, "/bottoken/sendMessage", r.URL.Path)
-		q := r.URL.Query()
-		assert.Equal(t, "@chat_id", q.Get("chat_id"))
-		assert.Equal(t, "Markdown", q.Get("parse_mode"))
-		assert.Equal(t, "true", q.Get("disable_web_page_preview"))
+		assert.Empty(t, r.URL.RawQuery)
 
 		var msg tgMsg
 		assert.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
-		assert.Equal(t, "text", msg.Text)
-		assert.Equal(t, "MarkdownV2", msg.ParseMode)
+		msgs = append(msgs, msg)
 
 		_, err := w.Write([]byte(`{"ok": true}`))
 		assert.NoError(t, err)
-		w.WriteHeader(http.StatusOK)
 	}))
 	defer ts.Close()
 
@@ -47,8 +45,129 @@ func TestTelegram_Send(t *testing.T) {
 		},
 		Token:                 "token",
 		DisableWebPagePreview: true,
+		MessageThreadID:       42,
+	})
+
+	t.Run("single message", func(t *testing.T) {
+		msgs = nil
+		err := svc.Send(context.Background(), "## v1.0.0\n- fix (#1)")
+		require.NoError(t, err)
+
+		assert.Equal(t, []tgMsg{{
+			ChatID:                "@chat_id",
+			MessageThreadID:       42,
+			Text:                  "*v1\\.0\\.0*\n• fix \\(\\#1\\)",
+			ParseMode:             "MarkdownV2",
+			DisableWebPagePreview: true,
+		}}, msgs)
+	})
+
+	t.Run("long message", func(t *testing.T) {
+		msgs = nil
+		line := strings.Repeat("a", 3000)
+		err := svc.Send(context.Background(), line+"\n"+line)
+		require.NoError(t, err)
+
+		require.Len(t, msgs, 2)
+		assert.Equal(t, line, msgs[0].Text)
+		assert.Equal(t, line, msgs[1].Text)
+	})
+
+	t.Run("long message, limit in utf-16", func(t *testing.T) {
+		msgs = nil
+		err := svc.Send(context.Background(), strings.Repeat("😀", 3000))
+		require.NoError(t, err)
+
+		require.Len(t, msgs, 2)
+		assert.Equal(t, strings.Repeat("😀", 2048), msgs[0].Text)
+		assert.Equal(t, strings.Repeat("😀", 952), msgs[1].Text)
+	})
+
+	t.Run("long message, code block is cut", func(t *testing.T) {
+		msgs = nil
+		a, b := strings.Repeat("a", 3000), strings.Repeat("b", 3000)
+		err := svc.Send(context.Background(), "```go\n"+a+"\n"+b+"\n```\ntext")
+		require.NoError(t, err)
+
+		require.Len(t, msgs, 2)
+		assert.Equal(t, "```go\n"+a+"\n```", msgs[0].Text)
+		assert.Equal(t, "```go\n"+b+"\n```\ntext", msgs[1].Text)
+	})
+}
+
+func TestTelegram_Send_Error(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte(`{"ok": false, "description": "Bad Request: can't parse entities"}`))
+		assert.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	svc := NewTelegram(TelegramParams{
+		ChatID: "123",
+		Client: http.Client{
+			Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				req.URL.Host = ts.URL[7:]
+				req.URL.Scheme = "http"
+				return http.DefaultTransport.RoundTrip(req)
+			}),
+		},
 	})
 
 	err := svc.Send(context.Background(), "text")
-	require.NoError(t, err)
+	assert.EqualError(t, err, `send message 1/1: unexpected telegram API status code 400, `+
+		`error: "Bad Request: can't parse entities"`)
+}
+
+func TestTelegram_Send_RetryAfter(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, err := w.Write([]byte(`{"ok": false, "error_code": 429, ` +
+			`"description": "Too Many Requests: retry after 7", "parameters": {"retry_after": 7}}`))
+		assert.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	svc := NewTelegram(TelegramParams{
+		ChatID: "123",
+		Client: http.Client{
+			Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				req.URL.Host = ts.URL[7:]
+				req.URL.Scheme = "http"
+				return http.DefaultTransport.RoundTrip(req)
+			}),
+		},
+	})
+
+	after, ok := retryAfter(svc.Send(context.Background(), "text"))
+	assert.True(t, ok)
+	assert.Equal(t, 7*time.Second, after)
+}
+
+func TestTgMarkdownV2(t *testing.T) {
+	tbl := []struct {
+		name, in, want string
+	}{
+		{name: "plain text", in: "version 1.0.0 - released!", want: "version 1\\.0\\.0 \\- released\\!"},
+		{name: "header", in: "## **Features** #", want: "*Features*"},
+		{name: "list", in: "- item\n  * nested\n1. first", want: "• item\n  • nested\n1\\. first"},
+		{name: "bold and italic", in: "**bold _italic_** and *italic*", want: "*bold _italic_* and _italic_"},
+		{name: "snake case", in: "some_snake_case", want: "some\\_snake\\_case"},
+		{name: "strikethrough", in: "~~old~~", want: "~old~"},
+		{
+			name: "link",
+			in:   "[PR #1](https://github.com/a/b/pull/1?q=(x)",
+			want: "[PR \\#1](https://github.com/a/b/pull/1?q=(x)",
+		},
+		{name: "inline code", in: "use `a_b.c\\d` now", want: "use `a_b.c\\\\d` now"},
+		{name: "code block", in: "```go\nfmt.Println(\"`\")\n```", want: "```go\nfmt.Println(\"\\`\")\n```"},
+		{name: "unclosed code block", in: "```\ncode", want: "```\ncode\n```"},
+		{name: "blockquote", in: "> quote *it*", want: ">quote _it_"},
+	}
+
+	for _, tt := range tbl {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tgMarkdownV2(tt.in))
+		})
+	}
 }
@@ -0,0 +1,182 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Semior001/releaseit/app/service/eval"
+	"github.com/samber/lo"
+)
+
+// DefaultWebhookBodyTmpl is the template of the webhook body, which is used,
+// if no other template is specified. It makes the same body as Post does.
+const DefaultWebhookBodyTmpl = `{"text": {{ toJson .Text }}}`
+
+// Webhook sends release notes to the arbitrary HTTP endpoint, with the body
+// made by the template, which has access to all fields of the release.
+type Webhook struct {
+	WebhookParams
+}
+
+// WebhookParams describes parameters to initialize webhook notifier.
+type WebhookParams struct {
+	Log       *log.Logger
+	Client    http.Client
+	Evaluator *eval.Evaluator
+	URL       string
+	Method    string // POST by default
+	Headers   map[string]string
+	// BodyTmplText is the template of the request body,
+	// DefaultWebhookBodyTmpl is used, if empty.
+	BodyTmplText string
+	Extras       map[string]string
+	// AcceptedStatuses are ranges of status codes, which are considered
+	// successful, if empty, any 2xx status code is accepted.
+	AcceptedStatuses []StatusRange
+	// Secret is used to sign the body with HMAC-SHA256, if empty,
+	// the body is not signed.
+	Secret string
+	// SignatureHeader is the header to put the signature in,
+	// X-Signature-256 by default. The signature is in format
+	// "sha256=<hex digest>".
+	SignatureHeader string
+}
+
+// NewWebhook makes a new Webhook notifier.
+func NewWebhook(params WebhookParams) (*Webhook, error) {
+	if params.Method == "" {
+		params.Method = http.MethodPost
+	}
+
+	if params.BodyTmplText == "" {
+		params.BodyTmplText = DefaultWebhookBodyTmpl
+	}
+
+	if params.SignatureHeader == "" {
+		params.SignatureHeader = "X-Signature-256"
+	}
+
+	if len(params.AcceptedStatuses) == 0 {
+		params.AcceptedStatuses = []StatusRange{{From: 200, To: 299}}
+	}
+
+	// headers are given in format "Name: value", so the value
+	// is likely to be prefixed with a space
+	headers := make(map[string]string, len(params.Headers))
+	for k, v := range params.Headers {
+		headers[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	params.Headers = headers
+
+	if err := params.Evaluator.Validate(params.BodyTmplText); err != nil {
+		return nil, fmt.Errorf("invalid body template: %w", err)
+	}
+
+	return &Webhook{WebhookParams: params}, nil
+}
+
+// String returns the name of the notifier.
+func (w *Webhook) String() string {
+	return fmt.Sprintf("webhook %s to %s", w.Method, extractBaseURL(w.URL))
+}
+
+type webhookTmplData struct {
+	Release
+	Extras map[string]string
+}
+
+// Send sends the request with the release to the webhook.
+func (w *Webhook) Send(ctx context.Context, rel Release) error {
+	body, err := w.Evaluator.Evaluate(ctx, w.BodyTmplText, webhookTmplData{Release: rel, Extras: w.Extras})
+	if err != nil {
+		return fmt.Errorf("build body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, w.Method, w.URL, strings.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range w.Headers {
+		req.Header.Set(k, v)
+	}
+
+	if w.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(w.Secret))
+		_, _ = mac.Write([]byte(body))
+		req.Header.Set(w.SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("do request: %w", err)
+	}
+	defer func() {
+		if err = resp.Body.Close(); err != nil {
+			w.Log.Printf("[WARN] can't close request body, %s", err)
+		}
+	}()
+
+	accepted := lo.ContainsBy(w.AcceptedStatuses, func(r StatusRange) bool { return r.Contains(resp.StatusCode) })
+	if !accepted {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return checkRetryable(resp, fmt.Errorf("unexpected status code: %d, message: %q", resp.StatusCode, msg))
+	}
+
+	return nil
+}
+
+// StatusRange is an inclusive range of HTTP status codes.
+type StatusRange struct {
+	From, To int
+}
+
+// ParseStatusRange parses the range of status codes, it might be
+// a single code ("204"), a range ("200-299") or a class ("2xx").
+func ParseStatusRange(s string) (StatusRange, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	if len(s) == 3 && strings.HasSuffix(s, "xx") {
+		class, err := strconv.Atoi(s[:1])
+		if err != nil || class < 1 || class > 5 {
+			return StatusRange{}, fmt.Errorf("invalid status class %q", s)
+		}
+		return StatusRange{From: class * 100, To: class*100 + 99}, nil
+	}
+
+	from, to, isRange := strings.Cut(s, "-")
+	if !isRange {
+		to = from
+	}
+
+	var r StatusRange
+	var err error
+
+	if r.From, err = strconv.Atoi(strings.TrimSpace(from)); err != nil {
+		return StatusRange{}, fmt.Errorf("parse status code %q: %w", from, err)
+	}
+
+	if r.To, err = strconv.Atoi(strings.TrimSpace(to)); err != nil {
+		return StatusRange{}, fmt.Errorf("parse status code %q: %w", to, err)
+	}
+
+	if r.From > r.To {
+		return StatusRange{}, fmt.Errorf("invalid status range %q", s)
+	}
+
+	return r, nil
+}
+
+// Contains checks whether the status code is in the range.
+func (r StatusRange) Contains(code int) bool {
+	return code >= r.From && code <= r.To
+}
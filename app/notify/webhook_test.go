@@ -0,0 +1,155 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Semior001/releaseit/app/git"
+	"github.com/Semior001/releaseit/app/service/eval"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhook_Send(t *testing.T) {
+	t.Run("templated body with signature", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPut, r.Method)
+			assert.Equal(t, "/releases", r.URL.Path)
+			assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			assert.Equal(t, `{"service": "api", "notes": "## v1.0.0\n- \"quoted\" fix"}`, string(body))
+
+			mac := hmac.New(sha256.New, []byte("secret"))
+			_, _ = mac.Write(body)
+			assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), r.Header.Get("X-Hub-Signature-256"))
+
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer ts.Close()
+
+		svc, err := NewWebhook(WebhookParams{
+			Log:             log.Default(),
+			Evaluator:       &eval.Evaluator{},
+			URL:             ts.URL + "/releases",
+			Method:          http.MethodPut,
+			Headers:         map[string]string{"Authorization": "Bearer token"},
+			BodyTmplText:    `{"service": {{ toJson .Extras.service }}, "notes": {{ toJson .Text }}}`,
+			Extras:          map[string]string{"service": "api"},
+			Secret:          "secret",
+			SignatureHeader: "X-Hub-Signature-256",
+		})
+		require.NoError(t, err)
+		assert.NoError(t, svc.Send(context.Background(), Release{Text: "## v1.0.0\n- \"quoted\" fix"}))
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Empty(t, r.Header.Get("X-Signature-256"))
+
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			assert.Equal(t, `{"text": "release notes"}`, string(body))
+
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer ts.Close()
+
+		svc, err := NewWebhook(WebhookParams{Log: log.Default(), Evaluator: &eval.Evaluator{}, URL: ts.URL})
+		require.NoError(t, err)
+		assert.NoError(t, svc.Send(context.Background(), Release{Text: "release notes"}))
+	})
+
+	t.Run("release fields", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			assert.Equal(t, `{"version": "v1.0.0", "prs": [1,2], "categories": ["Features"]}`, string(body))
+		}))
+		defer ts.Close()
+
+		svc, err := NewWebhook(WebhookParams{
+			Log:          log.Default(),
+			Evaluator:    &eval.Evaluator{},
+			URL:          ts.URL,
+			BodyTmplText: `{"version": {{ toJson .Version }}, "prs": [{{ range $i, $pr := .PRs }}{{ if $i }},{{ end }}{{ $pr.Number }}{{ end }}], "categories": [{{ range .Categories }}{{ toJson .Title }}{{ end }}]}`,
+		})
+		require.NoError(t, err)
+
+		err = svc.Send(context.Background(), Release{
+			Version:    "v1.0.0",
+			PRs:        []git.PullRequest{{Number: 1}, {Number: 2}},
+			Categories: []Category{{Title: "Features", PRs: []git.PullRequest{{Number: 1}}}},
+			Text:       "release notes",
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("status is not accepted", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+			_, err := w.Write([]byte("created"))
+			require.NoError(t, err)
+		}))
+		defer ts.Close()
+
+		svc, err := NewWebhook(WebhookParams{
+			Log:              log.Default(),
+			Evaluator:        &eval.Evaluator{},
+			URL:              ts.URL,
+			AcceptedStatuses: []StatusRange{{From: 200, To: 200}, {From: 202, To: 204}},
+		})
+		require.NoError(t, err)
+
+		err = svc.Send(context.Background(), Release{Text: "release notes"})
+		assert.EqualError(t, err, `unexpected status code: 201, message: "created"`)
+	})
+}
+
+func TestNewWebhook_InvalidTemplate(t *testing.T) {
+	_, err := NewWebhook(WebhookParams{Evaluator: &eval.Evaluator{}, BodyTmplText: "{{ .Text"})
+	assert.ErrorContains(t, err, "invalid body template")
+}
+
+func TestWebhook_String(t *testing.T) {
+	svc, err := NewWebhook(WebhookParams{Evaluator: &eval.Evaluator{}, URL: "https://example.com/hooks/releases"})
+	require.NoError(t, err)
+	assert.Equal(t, "webhook POST to https://example.com", svc.String())
+}
+
+func TestParseStatusRange(t *testing.T) {
+	tbl := []struct {
+		in      string
+		want    StatusRange
+		wantErr string
+	}{
+		{in: "204", want: StatusRange{From: 204, To: 204}},
+		{in: "200-299", want: StatusRange{From: 200, To: 299}},
+		{in: " 3XX ", want: StatusRange{From: 300, To: 399}},
+		{in: "9xx", wantErr: `invalid status class "9xx"`},
+		{in: "299-200", wantErr: `invalid status range "299-200"`},
+		{in: "abc", wantErr: `parse status code "abc": strconv.Atoi: parsing "abc": invalid syntax`},
+	}
+
+	for _, tt := range tbl {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseStatusRange(tt.in)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -21,6 +21,12 @@ type Config struct {
 	Template     string   `yaml:"template"`      // template for a changelog.
 	UnusedTitle  string   `yaml:"unused_title"`  // if set, the unused category will be built under this title at the, end of the changelog
 	IgnoreLabels []string `yaml:"ignore_labels"` // labels for pull requests, which won't be in release notes
+
+	// named templates, which might be assigned to destinations instead of the default one
+	Templates map[string]string `yaml:"templates"`
+	// names of templates by names of destinations, e.g. "telegram: short",
+	// destinations without the assigned template receive the default one
+	Destinations map[string]string `yaml:"destinations"`
 }
 
 // CategoryConfig describes the category configuration.
@@ -45,6 +51,18 @@ func (c *Config) validate() error {
 		return errors.New("template is empty")
 	}
 
+	for name, tmpl := range c.Templates {
+		if strings.TrimSpace(tmpl) == "" {
+			return fmt.Errorf("template %q is empty", name)
+		}
+	}
+
+	for dest, name := range c.Destinations {
+		if _, ok := c.Templates[name]; !ok {
+			return fmt.Errorf("template %q for destination %q is not defined", name, dest)
+		}
+	}
+
 	for idx, category := range c.Categories {
 		if category.Branch != "" {
 			re, err := regexp.Compile(category.Branch)
@@ -20,6 +20,21 @@ func TestConfig_validate(t *testing.T) {
 		assert.ErrorContains(t, cfg.validate(), "template is empty")
 	})
 
+	t.Run("empty named template", func(t *testing.T) {
+		cfg := Config{Categories: []CategoryConfig{{}}, Template: "test", Templates: map[string]string{"short": " "}}
+		assert.EqualError(t, cfg.validate(), `template "short" is empty`)
+	})
+
+	t.Run("undefined template for destination", func(t *testing.T) {
+		cfg := Config{
+			Categories:   []CategoryConfig{{}},
+			Template:     "test",
+			Templates:    map[string]string{"short": "short"},
+			Destinations: map[string]string{"telegram": "full"},
+		}
+		assert.EqualError(t, cfg.validate(), `template "full" for destination "telegram" is not defined`)
+	})
+
 	t.Run("invalid regexp", func(t *testing.T) {
 		cfg := Config{Categories: []CategoryConfig{{Branch: `[\]`}}, Template: "test"}
 		assert.ErrorContains(t, cfg.validate(), "invalid regexp for branch")
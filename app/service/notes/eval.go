@@ -18,8 +18,16 @@ import (
 // EvalAddon is an addon to evaluator, to be used in release notes template.
 type EvalAddon struct {
 	TaskTracker *tengine.Tracker
+	// ClosingRefs makes loadTicketsTree to attach pull requests also to the
+	// tickets, which are referenced in their bodies after closing keywords,
+	// e.g. "Closes #42".
+	ClosingRefs bool
 }
 
+// closingKeywordsRx matches closing keywords of github and gitlab,
+// which precede the reference to the closed issue.
+const closingKeywordsRx = `(?i:\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?):?\s+)`
+
 // String returns addon name.
 func (e *EvalAddon) String() string { return "release-notes" }
 
@@ -164,20 +172,35 @@ func (e *EvalAddon) loadTicketsTree(ctx context.Context) func(string, bool, []gi
 			return LoadedTree{}, fmt.Errorf("compile regexp: %w", err)
 		}
 
+		// the keywords group is non-capturing, so the ticket ID remains the first submatch
+		closingRx, err := regexp.Compile(closingKeywordsRx + "(?:" + ticketIDRx + ")")
+		if err != nil {
+			return LoadedTree{}, fmt.Errorf("compile closing keywords regexp: %w", err)
+		}
+
 		ticketPRs := map[string][]git.PullRequest{} // ticketID -> PR index
 		ticketCommits := map[string][]git.Commit{}  // ticketID -> commit hash
 		var unattachedPRs []git.PullRequest
 		var unattachedCommits []git.Commit
 
 		for _, pr := range prs {
-			submatches := rx.FindAllStringSubmatch(pr.Title, -1)
-			if len(submatches) == 0 {
+			var ticketIDs []string
+			for _, submatch := range rx.FindAllStringSubmatch(pr.Title, -1) {
+				ticketIDs = append(ticketIDs, submatch[1])
+			}
+
+			if e.ClosingRefs {
+				for _, submatch := range closingRx.FindAllStringSubmatch(pr.Body, -1) {
+					ticketIDs = append(ticketIDs, submatch[1])
+				}
+			}
+
+			if len(ticketIDs) == 0 {
 				unattachedPRs = append(unattachedPRs, pr)
 				continue
 			}
 
-			for _, submatch := range submatches {
-				ticketID := submatch[1]
+			for _, ticketID := range lo.Uniq(ticketIDs) {
 				ticketPRs[ticketID] = append(ticketPRs[ticketID], pr)
 			}
 		}
@@ -284,3 +284,47 @@ func TestEvalAddon_loadTicketTree(t *testing.T) {
 
 	assert.Equal(t, testData(t, "load-tree.txt"), buf.String())
 }
+
+func TestEvalAddon_loadTicketTree_closingRefs(t *testing.T) {
+	prs := []git.PullRequest{
+		{Title: "[#1] title reference"},
+		{Title: "fix bug", Body: "Fixes #2 and closes: #3, related to #4"},
+		{Title: "mentions only", Body: "follow-up of #1"},
+	}
+
+	newAddon := func(closingRefs bool) *EvalAddon {
+		return &EvalAddon{ClosingRefs: closingRefs, TaskTracker: &tengine.Tracker{
+			Interface: &tengine.InterfaceMock{
+				ListFunc: func(ctx context.Context, ids []string) ([]task.Ticket, error) {
+					return lo.Map(ids, func(id string, _ int) task.Ticket { return task.Ticket{ID: id} }), nil
+				},
+			},
+		}}
+	}
+
+	prTitles := func(tree LoadedTree) map[string][]string {
+		res := map[string][]string{}
+		for _, node := range tree.Roots {
+			res[node.ID] = lo.Map(node.PRs, func(pr git.PullRequest, _ int) string { return pr.Title })
+		}
+		return res
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		tree, err := newAddon(false).loadTicketsTree(context.Background())(`\[(#\d+)\]`, false, prs, nil)
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]string{"#1": {"[#1] title reference"}}, prTitles(tree))
+		assert.Len(t, tree.UnattachedPRs, 2)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		tree, err := newAddon(true).loadTicketsTree(context.Background())(`(#\d+)`, false, prs, nil)
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]string{
+			"#1": {"[#1] title reference"},
+			"#2": {"fix bug"},
+			"#3": {"fix bug"},
+		}, prTitles(tree))
+		assert.Equal(t, []git.PullRequest{prs[2]}, tree.UnattachedPRs)
+	})
+}
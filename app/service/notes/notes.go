@@ -43,16 +43,61 @@ type BuildRequest struct {
 	Commits   []git.Commit
 }
 
-// Build builds the changelog for the tag.
-func (s *Builder) Build(ctx context.Context, req BuildRequest) (string, error) {
-	data := tmplData{
+// Notes are the release notes, built for all destinations.
+type Notes struct {
+	Categories []Category
+	Text       string            // release notes, rendered with the default template
+	Texts      map[string]string // release notes, rendered with assigned templates, by destination names
+}
+
+// BuildAll builds the changelog with the default template and with templates,
+// assigned to destinations. Each template is rendered only once, even if it
+// is assigned to several destinations.
+func (s *Builder) BuildAll(ctx context.Context, req BuildRequest) (Notes, error) {
+	data := s.tmplData(req)
+
+	text, err := s.Evaluator.Evaluate(ctx, s.Template, data)
+	if err != nil {
+		return Notes{}, fmt.Errorf("executing template for changelog: %w", err)
+	}
+
+	res := Notes{Categories: data.Categories, Text: text, Texts: make(map[string]string, len(s.Destinations))}
+	rendered := map[string]string{}
+	for dest, name := range s.Destinations {
+		if text, ok := rendered[name]; ok {
+			res.Texts[dest] = text
+			continue
+		}
+
+		if text, err = s.Evaluator.Evaluate(ctx, s.Templates[name], data); err != nil {
+			return Notes{}, fmt.Errorf("executing template %q for changelog: %w", name, err)
+		}
+
+		rendered[name] = text
+		res.Texts[dest] = text
+	}
+
+	return res, nil
+}
+
+func (s *Builder) tmplData(req BuildRequest) tmplData {
+	return tmplData{
 		From:         req.From,
 		To:           req.To,
 		Date:         s.now(),
 		Extras:       s.Extras,
 		Total:        len(req.ClosedPRs),
 		TotalCommits: len(req.Commits),
+		Categories:   s.Categorize(req),
 	}
+}
+
+// Categorize splits pull requests and commits of the request into
+// categories, in order of their appearance in the config. Pull requests
+// with ignored labels are omitted, the rest of pull requests and commits
+// are put into the "unused" category, if it is set.
+func (s *Builder) Categorize(req BuildRequest) []Category {
+	var categories []Category
 
 	usedPRs := make([]bool, len(req.ClosedPRs))
 	usedCommits := make([]bool, len(req.Commits))
@@ -62,7 +107,7 @@ func (s *Builder) Build(ctx context.Context, req BuildRequest) (string, error) {
 	}
 
 	for _, category := range s.Categories {
-		categoryData := categoryTmplData{Title: category.Title}
+		categoryData := Category{Title: category.Title}
 
 		for i, pr := range req.ClosedPRs {
 			if len(lo.Intersect(pr.Labels, s.IgnoreLabels)) > 0 {
@@ -87,7 +132,7 @@ func (s *Builder) Build(ctx context.Context, req BuildRequest) (string, error) {
 		}
 
 		s.sortPRs(categoryData.PRs)
-		data.Categories = append(data.Categories, categoryData)
+		categories = append(categories, categoryData)
 	}
 
 	for categoryIdx, category := range s.Categories {
@@ -105,12 +150,12 @@ func (s *Builder) Build(ctx context.Context, req BuildRequest) (string, error) {
 			}
 
 			usedCommits[idx] = true
-			data.Categories[categoryIdx].Commits = append(data.Categories[categoryIdx].Commits, commit)
+			categories[categoryIdx].Commits = append(categories[categoryIdx].Commits, commit)
 		}
 	}
 
 	if s.UnusedTitle != "" {
-		category := categoryTmplData{Title: s.UnusedTitle}
+		category := Category{Title: s.UnusedTitle}
 
 		for i, pr := range req.ClosedPRs {
 			if usedPRs[i] {
@@ -136,16 +181,11 @@ func (s *Builder) Build(ctx context.Context, req BuildRequest) (string, error) {
 
 		if len(category.PRs) > 0 || len(category.Commits) > 0 {
 			s.sortPRs(category.PRs)
-			data.Categories = append(data.Categories, category)
+			categories = append(categories, category)
 		}
 	}
 
-	res, err := s.Evaluator.Evaluate(ctx, s.Template, data)
-	if err != nil {
-		return "", fmt.Errorf("executing template for changelog: %w", err)
-	}
-
-	return res, nil
+	return categories
 }
 
 func (s *Builder) sortPRs(prs []git.PullRequest) {
@@ -190,10 +230,11 @@ type tmplData struct {
 	Extras       map[string]string
 	Total        int // total number of PRs
 	TotalCommits int // total number of commits
-	Categories   []categoryTmplData
+	Categories   []Category
 }
 
-type categoryTmplData struct {
+// Category is a group of pull requests and commits of the release.
+type Category struct {
 	Title   string
 	PRs     []git.PullRequest
 	Commits []git.Commit
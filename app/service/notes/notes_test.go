@@ -5,6 +5,7 @@ import (
 	"embed"
 	"regexp"
 	"testing"
+	"text/template"
 	"time"
 
 	"github.com/Semior001/releaseit/app/git"
@@ -22,7 +23,7 @@ func testData(t *testing.T, key string) string {
 	return string(b)
 }
 
-func TestBuilder_Build(t *testing.T) {
+func TestBuilder_BuildAll(t *testing.T) {
 	tm := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
 
 	svc, err := NewBuilder(Config{
@@ -96,10 +97,46 @@ func TestBuilder_Build(t *testing.T) {
 		},
 	}
 
-	txt, err := svc.Build(context.Background(), req)
+	rn, err := svc.BuildAll(context.Background(), req)
 	require.NoError(t, err)
 
-	assert.Equal(t, testData(t, "release-notes.txt"), txt)
+	assert.Equal(t, testData(t, "release-notes.txt"), rn.Text)
+	assert.Empty(t, rn.Texts)
+}
+
+func TestBuilder_BuildAll_Destinations(t *testing.T) {
+	renders := 0
+	evaler := &eval.Evaluator{Addon: &eval.AddonMock{
+		FuncsFunc: func(context.Context) (template.FuncMap, error) {
+			return template.FuncMap{"render": func() string { renders++; return "" }}, nil
+		},
+		StringFunc: func() string { return "mock" },
+	}}
+
+	svc, err := NewBuilder(Config{
+		Categories: []CategoryConfig{{Title: "Features", Labels: []string{"feature"}}},
+		Template:   `{{ render }}full {{.To}}:{{range .Categories}} {{.Title}}{{range .PRs}} #{{.Number}}{{end}}{{end}}`,
+		Templates: map[string]string{
+			"short":  `{{ render }}short {{.To}}: {{.Total}} changes`,
+			"unused": `{{ render }}unused`,
+		},
+		Destinations: map[string]string{"telegram": "short", "slack": "short"},
+	}, evaler, nil)
+	require.NoError(t, err)
+
+	rn, err := svc.BuildAll(context.Background(), BuildRequest{
+		From:      "v0.1.0",
+		To:        "v0.2.0",
+		ClosedPRs: []git.PullRequest{{Number: 1, Labels: []string{"feature"}}, {Number: 2}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, Notes{
+		Categories: []Category{{Title: "Features", PRs: []git.PullRequest{{Number: 1, Labels: []string{"feature"}}}}},
+		Text:       "full v0.2.0: Features #1",
+		Texts:      map[string]string{"telegram": "short v0.2.0: 2 changes", "slack": "short v0.2.0: 2 changes"},
+	}, rn)
+	assert.Equal(t, 2, renders, "each used template must be rendered once")
 }
 
 func TestBuilder_sortPRs(t *testing.T) {
@@ -54,13 +54,26 @@ func (s *Service) Changelog(ctx context.Context, fromExpr, toExpr string) error
 	}
 
 	log.Printf("[DEBUG] building release notes for %d pull requests", len(req.ClosedPRs))
-	text, err := s.ReleaseNotesBuilder.Build(ctx, req)
+	rn, err := s.ReleaseNotesBuilder.BuildAll(ctx, req)
 	if err != nil {
 		return fmt.Errorf("build release notes: %w", err)
 	}
 
+	rel := notify.Release{
+		From:    from,
+		To:      to,
+		Version: to,
+		Categories: lo.Map(rn.Categories, func(c notes.Category, _ int) notify.Category {
+			return notify.Category{Title: c.Title, PRs: c.PRs, Commits: c.Commits}
+		}),
+		PRs:     req.ClosedPRs,
+		Commits: req.Commits,
+		Text:    rn.Text,
+		Texts:   rn.Texts,
+	}
+
 	log.Printf("[DEBUG] sending release notes to destinations")
-	if err = s.Notifier.Send(ctx, text); err != nil {
+	if err = s.Notifier.Send(ctx, rel); err != nil {
 		return fmt.Errorf("notify: %w", err)
 	}
 
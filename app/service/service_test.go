@@ -54,7 +54,7 @@ func TestService_Changelog(t *testing.T) {
 
 	t.Run("commit SHAs provided", func(t *testing.T) {
 		now := time.Now()
-		buf := &strings.Builder{}
+		dest := &notify.DestinationMock{SendFunc: func(context.Context, notify.Release) error { return nil }}
 
 		eng := &gengine.InterfaceMock{
 			CompareFunc: func(ctx context.Context, from, to string) (git.CommitsComparison, error) {
@@ -104,13 +104,23 @@ func TestService_Changelog(t *testing.T) {
 				Template:     `{{range .Categories}}{{.Title}}:{{range .PRs}} {{.Number}}{{end}} {{end}}`,
 				UnusedTitle:  "Unused",
 			}, &eval.Evaluator{}, nil)),
-			Notifier: &notify.WriterNotifier{Writer: buf, Name: "buf"},
+			Notifier: dest,
 		}
 
 		err := svc.Changelog(context.Background(), "from", "to")
 		require.NoError(t, err)
 
-		require.Equal(t, "Features: 1 Bug fixes: 2 Unused: 4 ", buf.String())
+		require.Len(t, dest.SendCalls(), 1)
+		rel := dest.SendCalls()[0].Rel
+		require.Equal(t, "Features: 1 Bug fixes: 2 Unused: 4 ", rel.Text)
+		assert.Equal(t, "from", rel.From)
+		assert.Equal(t, "to", rel.To)
+		assert.Equal(t, "to", rel.Version)
+		assert.Len(t, rel.Commits, 4)
+		assert.ElementsMatch(t, []int{1, 2, 4}, lo.Map(rel.PRs, func(pr git.PullRequest, _ int) int { return pr.Number }))
+		assert.Equal(t, []string{"Features", "Bug fixes", "Unused"},
+			lo.Map(rel.Categories, func(c notify.Category, _ int) string { return c.Title }))
+		assert.Equal(t, 2, rel.Categories[1].PRs[0].Number)
 	})
 
 	t.Run("commits only mode", func(t *testing.T) {
@@ -144,7 +154,7 @@ func TestService_Changelog(t *testing.T) {
 				Template:     `{{range .Categories}}{{.Title}}:{{range .Commits}} {{.SHA}},{{end}};{{end}}`,
 				UnusedTitle:  "Unused",
 			}, &eval.Evaluator{}, nil)),
-			Notifier:    &notify.WriterNotifier{Writer: buf, Name: "buf"},
+			Notifier:    notify.Text(&notify.WriterNotifier{Writer: buf, Name: "buf"}),
 			CommitsOnly: true,
 		}
 
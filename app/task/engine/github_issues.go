@@ -0,0 +1,269 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Semior001/releaseit/app/git/ghclient"
+	"github.com/Semior001/releaseit/app/task"
+	"github.com/go-pkgz/requester"
+	"github.com/go-pkgz/requester/middleware"
+	"github.com/go-pkgz/requester/middleware/logger"
+	gh "github.com/google/go-github/v37/github"
+	"golang.org/x/sync/errgroup"
+)
+
+// issuesConcurrency is the maximum number of concurrent requests
+// to list issues one by one.
+const issuesConcurrency = 10
+
+// GithubIssues is a task tracker engine, which takes issues of the github
+// repository as tasks. Issues are referenced as #42, milestones as %3.
+// The parent of the issue is its parent issue, if it's a sub-issue,
+// or its milestone otherwise.
+type GithubIssues struct {
+	cl      *gh.Client
+	parents parentsCache
+
+	GithubIssuesParams
+}
+
+// GithubIssuesParams is a set of parameters for GithubIssues engine.
+type GithubIssuesParams struct {
+	Owner             string
+	Name              string
+	BaseURL           string // api url of github enterprise server, api.github.com by default
+	BasicAuthUsername string
+	BasicAuthPassword string
+	Token             string // personal access token
+	App               ghclient.AppParams
+	HTTPClient        http.Client
+}
+
+// NewGithubIssues creates a new GithubIssues engine.
+func NewGithubIssues(ctx context.Context, params GithubIssuesParams) (*GithubIssues, error) {
+	svc := &GithubIssues{GithubIssuesParams: params}
+
+	cl := requester.New(params.HTTPClient,
+		logger.New(logger.Func(log.Printf), logger.Prefix("[DEBUG]")).Middleware,
+	)
+
+	switch {
+	case params.Token != "":
+		cl.Use(middleware.Header("Authorization", "Bearer "+params.Token))
+	case !params.App.Empty():
+		inst, err := ghclient.NewInstallation(params.App, params.Owner, params.Name, params.BaseURL, params.HTTPClient)
+		if err != nil {
+			return nil, fmt.Errorf("prepare github app installation: %w", err)
+		}
+		cl.Use(inst.Middleware)
+	case params.BasicAuthUsername != "" && params.BasicAuthPassword != "":
+		cl.Use(middleware.BasicAuth(params.BasicAuthUsername, params.BasicAuthPassword))
+	}
+
+	var err error
+	if svc.cl, err = ghclient.New(cl.Client(), params.BaseURL); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultSetupTimeout)
+	defer cancel()
+
+	if _, _, err = svc.cl.Repositories.Get(ctx, svc.Owner, svc.Name); err != nil {
+		return nil, fmt.Errorf("check connection to github: %w", err)
+	}
+
+	return svc, nil
+}
+
+// List lists issues and milestones by their references, non-existing ones are omitted.
+func (g *GithubIssues) List(ctx context.Context, ids []string) ([]task.Ticket, error) {
+	tickets := make([]*task.Ticket, len(ids))
+
+	ewg, ctx := errgroup.WithContext(ctx)
+	ewg.SetLimit(issuesConcurrency)
+	for idx, id := range ids {
+		idx, id := idx, id
+		ewg.Go(func() error {
+			ticket, err := g.Get(ctx, id)
+			switch {
+			case g.isNotFoundErr(err):
+				log.Printf("[DEBUG] github ticket %s is not found", id)
+				return nil
+			case err != nil:
+				return fmt.Errorf("get %s: %w", id, err)
+			}
+
+			tickets[idx] = &ticket
+			return nil
+		})
+	}
+
+	if err := ewg.Wait(); err != nil {
+		return nil, err
+	}
+
+	var res []task.Ticket
+	for _, ticket := range tickets {
+		if ticket != nil {
+			res = append(res, *ticket)
+		}
+	}
+
+	return res, nil
+}
+
+// Get returns a single issue or milestone by its reference.
+func (g *GithubIssues) Get(ctx context.Context, id string) (task.Ticket, error) {
+	ref, err := parseIssueRef(id)
+	if err != nil {
+		return task.Ticket{}, fmt.Errorf("invalid reference: %w", err)
+	}
+
+	switch ref.kind {
+	case '#':
+		return g.getIssue(ctx, ref.number)
+	case '%':
+		if ticket, ok := g.parents.get(ref.String()); ok {
+			return ticket, nil
+		}
+
+		milestone, _, err := g.cl.Issues.GetMilestone(ctx, g.Owner, g.Name, int(ref.number))
+		if err != nil {
+			return task.Ticket{}, fmt.Errorf("github returned error: %w", err)
+		}
+
+		return g.transformMilestone(milestone), nil
+	default:
+		return task.Ticket{}, fmt.Errorf("github doesn't support references to %q", ref.kind)
+	}
+}
+
+type githubIssue struct {
+	Number    int           `json:"number"`
+	Title     string        `json:"title"`
+	Body      string        `json:"body"`
+	HTMLURL   string        `json:"html_url"`
+	ClosedAt  *time.Time    `json:"closed_at"`
+	User      *gh.User      `json:"user"`
+	Assignee  *gh.User      `json:"assignee"`
+	Milestone *gh.Milestone `json:"milestone"`
+	// type of the issue, if the organization has issue types set up
+	Type *struct {
+		Name string `json:"name"`
+	} `json:"type"`
+	// set, if the issue is a pull request, as github returns them as issues too
+	PullRequest *struct{} `json:"pull_request"`
+}
+
+// errGithubPullRequest is returned, when the reference points to a pull
+// request, it's considered as a non-existing issue, as otherwise the pull
+// request, referenced by its own number, e.g. in a squash-merge title,
+// would be grouped under itself.
+var errGithubPullRequest = errors.New("reference is a pull request, not an issue")
+
+func (g *GithubIssues) getIssue(ctx context.Context, number int64) (task.Ticket, error) {
+	// issue types and sub-issues aren't supported by the client, so they're requested manually
+	var issue githubIssue
+	if err := g.get(ctx, fmt.Sprintf("repos/%s/%s/issues/%d", g.Owner, g.Name, number), &issue); err != nil {
+		return task.Ticket{}, fmt.Errorf("github returned error: %w", err)
+	}
+
+	if issue.PullRequest != nil {
+		return task.Ticket{}, fmt.Errorf("#%d: %w", number, errGithubPullRequest)
+	}
+
+	var parent githubIssue
+	err := g.get(ctx, fmt.Sprintf("repos/%s/%s/issues/%d/parent", g.Owner, g.Name, number), &parent)
+	if err != nil && !g.isNotFoundErr(err) {
+		return task.Ticket{}, fmt.Errorf("get parent issue: %w", err)
+	}
+
+	return g.transformIssue(issue, parent.Number), nil
+}
+
+func (g *GithubIssues) get(ctx context.Context, path string, v any) error {
+	req, err := g.cl.NewRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	if _, err = g.cl.Do(ctx, req, v); err != nil {
+		return fmt.Errorf("do request: %w", err)
+	}
+
+	return nil
+}
+
+func (g *GithubIssues) transformIssue(issue githubIssue, parentNumber int) task.Ticket {
+	ticket := task.Ticket{
+		ID:       fmt.Sprintf("#%d", issue.Number),
+		URL:      issue.HTMLURL,
+		Name:     issue.Title,
+		Body:     issue.Body,
+		Author:   g.transformUser(issue.User),
+		Assignee: g.transformUser(issue.Assignee),
+		Type:     task.TypeTask,
+		TypeRaw:  "issue",
+	}
+
+	if issue.ClosedAt != nil {
+		ticket.ClosedAt = *issue.ClosedAt
+	}
+
+	if issue.Type != nil {
+		ticket.TypeRaw = issue.Type.Name
+		if strings.EqualFold(issue.Type.Name, "epic") {
+			ticket.Type = task.TypeEpic
+		}
+	}
+
+	switch {
+	case parentNumber != 0:
+		ticket.ParentID = fmt.Sprintf("#%d", parentNumber)
+		if ticket.Type == task.TypeTask {
+			ticket.Type = task.TypeSubtask
+		}
+	case issue.Milestone != nil:
+		milestone := g.transformMilestone(issue.Milestone)
+		g.parents.put(milestone)
+		ticket.ParentID = milestone.ID
+	}
+
+	return ticket
+}
+
+func (g *GithubIssues) transformMilestone(milestone *gh.Milestone) task.Ticket {
+	return task.Ticket{
+		ID:       fmt.Sprintf("%%%d", milestone.GetNumber()),
+		URL:      milestone.GetHTMLURL(),
+		Name:     milestone.GetTitle(),
+		Body:     milestone.GetDescription(),
+		ClosedAt: milestone.GetClosedAt(),
+		Author:   g.transformUser(milestone.Creator),
+		Type:     task.TypeEpic,
+		TypeRaw:  "milestone",
+	}
+}
+
+func (g *GithubIssues) transformUser(user *gh.User) task.User {
+	if user == nil {
+		return task.User{}
+	}
+
+	return task.User{Username: user.GetLogin(), Email: user.GetEmail()}
+}
+
+func (g *GithubIssues) isNotFoundErr(err error) bool {
+	if errors.Is(err, errGithubPullRequest) {
+		return true
+	}
+
+	var rerr *gh.ErrorResponse
+	return errors.As(err, &rerr) && rerr.Response != nil && rerr.Response.StatusCode == http.StatusNotFound
+}
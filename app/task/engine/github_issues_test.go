@@ -0,0 +1,190 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Semior001/releaseit/app/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGithubIssues_List(t *testing.T) {
+	milestoneCalls := 0
+	svc := newGithubIssues(t, func(w http.ResponseWriter, r *http.Request) {
+		var resp any
+		switch r.URL.Path {
+		case "/api/v3/repos/owner/name/issues/1":
+			resp = J{
+				"number":    1,
+				"title":     "title",
+				"body":      "body",
+				"html_url":  "https://github.com/owner/name/issues/1",
+				"closed_at": time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+				"user":      J{"login": "author"},
+				"assignee":  J{"login": "assignee"},
+				"milestone": J{
+					"number":    3,
+					"title":     "v1.0.0",
+					"html_url":  "https://github.com/owner/name/milestone/3",
+					"closed_at": time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+					"creator":   J{"login": "author"},
+				},
+			}
+		case "/api/v3/repos/owner/name/issues/2":
+			resp = J{
+				"number":    2,
+				"title":     "title-2",
+				"html_url":  "https://github.com/owner/name/issues/2",
+				"user":      J{"login": "author"},
+				"milestone": J{"number": 3},
+			}
+		case "/api/v3/repos/owner/name/issues/6":
+			resp = J{
+				"number":       6,
+				"title":        "pull request",
+				"html_url":     "https://github.com/owner/name/pull/6",
+				"pull_request": J{"url": "https://api.github.com/repos/owner/name/pulls/6"},
+			}
+		case "/api/v3/repos/owner/name/issues/6/parent":
+			require.FailNow(t, "parent of the pull request must not be requested")
+		case "/api/v3/repos/owner/name/issues/2/parent":
+			resp = J{"number": 5, "title": "parent"}
+		case "/api/v3/repos/owner/name/issues/5":
+			resp = J{
+				"number":   5,
+				"title":    "parent",
+				"html_url": "https://github.com/owner/name/issues/5",
+				"type":     J{"name": "Epic"},
+			}
+		case "/api/v3/repos/owner/name/milestones/3":
+			milestoneCalls++
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			resp = J{"message": "Not Found"}
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(resp))
+	})
+
+	tickets, err := svc.List(context.Background(), []string{"#1", "2", "#4", "5", "#6"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []task.Ticket{
+		{
+			ID:       "#1",
+			ParentID: "%3",
+			URL:      "https://github.com/owner/name/issues/1",
+			Name:     "title",
+			Body:     "body",
+			ClosedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			Author:   task.User{Username: "author"},
+			Assignee: task.User{Username: "assignee"},
+			Type:     task.TypeTask,
+			TypeRaw:  "issue",
+		},
+		{
+			ID:       "#2",
+			ParentID: "#5",
+			URL:      "https://github.com/owner/name/issues/2",
+			Name:     "title-2",
+			Author:   task.User{Username: "author"},
+			Type:     task.TypeSubtask,
+			TypeRaw:  "issue",
+		},
+		{
+			ID:      "#5",
+			URL:     "https://github.com/owner/name/issues/5",
+			Name:    "parent",
+			Type:    task.TypeEpic,
+			TypeRaw: "Epic",
+		},
+	}, utcTimes(tickets))
+
+	milestone, err := svc.Get(context.Background(), "%3")
+	require.NoError(t, err)
+	assert.Equal(t, task.Ticket{
+		ID:       "%3",
+		URL:      "https://github.com/owner/name/milestone/3",
+		Name:     "v1.0.0",
+		ClosedAt: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+		Author:   task.User{Username: "author"},
+		Type:     task.TypeEpic,
+		TypeRaw:  "milestone",
+	}, utcTimes([]task.Ticket{milestone})[0])
+	assert.Zero(t, milestoneCalls, "milestone must be taken from the issue")
+}
+
+func TestGithubIssues_Get(t *testing.T) {
+	t.Run("milestone", func(t *testing.T) {
+		svc := newGithubIssues(t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/api/v3/repos/owner/name/milestones/3", r.URL.Path)
+			require.NoError(t, json.NewEncoder(w).Encode(J{"number": 3, "title": "v1.0.0"}))
+		})
+
+		ticket, err := svc.Get(context.Background(), "%3")
+		require.NoError(t, err)
+		assert.Equal(t, task.Ticket{ID: "%3", Name: "v1.0.0", Type: task.TypeEpic, TypeRaw: "milestone"}, ticket)
+	})
+
+	t.Run("pull request", func(t *testing.T) {
+		svc := newGithubIssues(t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/api/v3/repos/owner/name/issues/6", r.URL.Path)
+			require.NoError(t, json.NewEncoder(w).Encode(J{"number": 6, "pull_request": J{}}))
+		})
+
+		_, err := svc.Get(context.Background(), "#6")
+		assert.EqualError(t, err, "#6: reference is a pull request, not an issue")
+	})
+
+	t.Run("epic", func(t *testing.T) {
+		svc := newGithubIssues(t, func(w http.ResponseWriter, r *http.Request) {
+			require.FailNow(t, "unexpected request", r.URL.Path)
+		})
+
+		_, err := svc.Get(context.Background(), "&3")
+		assert.EqualError(t, err, "github doesn't support references to '&'")
+	})
+
+	t.Run("invalid reference", func(t *testing.T) {
+		svc := newGithubIssues(t, func(w http.ResponseWriter, r *http.Request) {
+			require.FailNow(t, "unexpected request", r.URL.Path)
+		})
+
+		_, err := svc.Get(context.Background(), "TASK-1")
+		assert.EqualError(t, err, `invalid reference: parse number of "TASK-1": `+
+			`strconv.ParseInt: parsing "TASK-1": invalid syntax`)
+	})
+}
+
+func newGithubIssues(t *testing.T, h http.HandlerFunc) *GithubIssues {
+	t.Helper()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		if r.URL.Path == "/api/v3/repos/owner/name" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		h(w, r)
+	}))
+	t.Cleanup(ts.Close)
+
+	svc, err := NewGithubIssues(context.Background(), GithubIssuesParams{
+		Owner:      "owner",
+		Name:       "name",
+		BaseURL:    ts.URL + "/",
+		Token:      "token",
+		HTTPClient: *ts.Client(),
+	})
+	require.NoError(t, err)
+
+	return svc
+}
@@ -0,0 +1,220 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/Semior001/releaseit/app/task"
+	"github.com/go-pkgz/requester"
+	"github.com/go-pkgz/requester/middleware/logger"
+	"github.com/samber/lo"
+	gl "gitlab.com/gitlab-org/api/client-go"
+	"golang.org/x/sync/errgroup"
+)
+
+// GitlabIssues is a task tracker engine, which takes issues of the gitlab
+// project as tasks. Issues are referenced as #42, milestones as %3 (by ID)
+// and epics as &5. The parent of the issue is its epic, if any,
+// or its milestone otherwise.
+type GitlabIssues struct {
+	cl      *gl.Client
+	parents parentsCache
+
+	GitlabIssuesParams
+}
+
+// GitlabIssuesParams is a set of parameters for GitlabIssues engine.
+type GitlabIssuesParams struct {
+	Token      string
+	BaseURL    string
+	ProjectID  string
+	HTTPClient http.Client
+}
+
+// NewGitlabIssues creates a new GitlabIssues engine.
+func NewGitlabIssues(ctx context.Context, params GitlabIssuesParams) (*GitlabIssues, error) {
+	svc := &GitlabIssues{GitlabIssuesParams: params}
+
+	rq := requester.New(params.HTTPClient,
+		logger.New(logger.Func(log.Printf), logger.Prefix("[DEBUG]")).Middleware,
+	)
+
+	var err error
+	svc.cl, err = gl.NewClient(
+		params.Token,
+		gl.WithBaseURL(params.BaseURL),
+		gl.WithHTTPClient(rq.Client()),
+		// client retries silently with backoff for up to half a minute,
+		// which exceeds the timeout of assembling the release
+		gl.WithoutRetries(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("initialize gitlab client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultSetupTimeout)
+	defer cancel()
+
+	if _, _, err = svc.cl.Projects.GetProject(params.ProjectID, &gl.GetProjectOptions{}, gl.WithContext(ctx)); err != nil {
+		return nil, fmt.Errorf("ping gitlab: %w", err)
+	}
+
+	return svc, nil
+}
+
+// List lists issues, milestones and epics by their references,
+// non-existing ones are omitted.
+func (g *GitlabIssues) List(ctx context.Context, ids []string) ([]task.Ticket, error) {
+	tickets := make([]*task.Ticket, len(ids))
+
+	ewg, ctx := errgroup.WithContext(ctx)
+	ewg.SetLimit(issuesConcurrency)
+	for idx, id := range ids {
+		idx, id := idx, id
+		ewg.Go(func() error {
+			ticket, err := g.Get(ctx, id)
+			switch {
+			case errors.Is(err, gl.ErrNotFound):
+				log.Printf("[DEBUG] gitlab ticket %s is not found", id)
+				return nil
+			case err != nil:
+				return fmt.Errorf("get %s: %w", id, err)
+			}
+
+			tickets[idx] = &ticket
+			return nil
+		})
+	}
+
+	if err := ewg.Wait(); err != nil {
+		return nil, err
+	}
+
+	var res []task.Ticket
+	for _, ticket := range tickets {
+		if ticket != nil {
+			res = append(res, *ticket)
+		}
+	}
+
+	return res, nil
+}
+
+// Get returns a single issue, milestone or epic by its reference.
+func (g *GitlabIssues) Get(ctx context.Context, id string) (task.Ticket, error) {
+	ref, err := parseIssueRef(id)
+	if err != nil {
+		return task.Ticket{}, fmt.Errorf("invalid reference: %w", err)
+	}
+
+	if ref.kind != '#' {
+		if ticket, ok := g.parents.get(ref.String()); ok {
+			return ticket, nil
+		}
+	}
+
+	switch ref.kind {
+	case '#':
+		issue, _, err := g.cl.Issues.GetIssue(g.ProjectID, ref.number, gl.WithContext(ctx))
+		if err != nil {
+			return task.Ticket{}, fmt.Errorf("gitlab returned error: %w", err)
+		}
+		return g.transformIssue(issue), nil
+	case '%':
+		milestone, _, err := g.cl.Milestones.GetMilestone(g.ProjectID, ref.number, gl.WithContext(ctx))
+		if err != nil {
+			return task.Ticket{}, fmt.Errorf("gitlab returned error: %w", err)
+		}
+		return g.transformMilestone(milestone), nil
+	default:
+		// epics belong to the group, which is known only from their issues
+		return task.Ticket{}, fmt.Errorf("epic %s is not referenced by any issue: %w", ref, gl.ErrNotFound)
+	}
+}
+
+func (g *GitlabIssues) transformIssue(issue *gl.Issue) task.Ticket {
+	ticket := task.Ticket{
+		ID:       fmt.Sprintf("#%d", issue.IID),
+		URL:      issue.WebURL,
+		Name:     issue.Title,
+		Body:     issue.Description,
+		ClosedAt: lo.FromPtr(issue.ClosedAt),
+		Type:     task.TypeTask,
+		TypeRaw:  lo.FromPtr(issue.IssueType),
+	}
+
+	if ticket.TypeRaw == "" {
+		ticket.TypeRaw = "issue"
+	}
+
+	if issue.Author != nil {
+		ticket.Author = task.User{Username: issue.Author.Username}
+	}
+
+	switch {
+	case len(issue.Assignees) > 0:
+		ticket.Assignee = task.User{Username: issue.Assignees[0].Username}
+	case issue.Assignee != nil:
+		ticket.Assignee = task.User{Username: issue.Assignee.Username}
+	}
+
+	switch strings.ToLower(ticket.TypeRaw) {
+	case "epic":
+		ticket.Type = task.TypeEpic
+	case "task":
+		ticket.Type = task.TypeSubtask
+	}
+
+	switch {
+	case issue.Epic != nil:
+		epic := g.transformEpic(issue.Epic)
+		g.parents.put(epic)
+		ticket.ParentID = epic.ID
+	case issue.Milestone != nil:
+		milestone := g.transformMilestone(issue.Milestone)
+		g.parents.put(milestone)
+		ticket.ParentID = milestone.ID
+	}
+
+	return ticket
+}
+
+func (g *GitlabIssues) transformMilestone(milestone *gl.Milestone) task.Ticket {
+	ticket := task.Ticket{
+		ID:      fmt.Sprintf("%%%d", milestone.ID),
+		URL:     milestone.WebURL,
+		Name:    milestone.Title,
+		Body:    milestone.Description,
+		Type:    task.TypeEpic,
+		TypeRaw: "milestone",
+	}
+
+	// gitlab doesn't tell, when the milestone was closed
+	if milestone.State == "closed" {
+		ticket.ClosedAt = lo.FromPtr(milestone.UpdatedAt)
+	}
+
+	return ticket
+}
+
+func (g *GitlabIssues) transformEpic(epic *gl.Epic) task.Ticket {
+	ticket := task.Ticket{
+		ID:       fmt.Sprintf("&%d", epic.IID),
+		URL:      epic.WebURL,
+		Name:     epic.Title,
+		Body:     epic.Description,
+		ClosedAt: lo.FromPtr(epic.ClosedAt),
+		Type:     task.TypeEpic,
+		TypeRaw:  "epic",
+	}
+
+	if epic.Author != nil {
+		ticket.Author = task.User{Username: epic.Author.Username}
+	}
+
+	return ticket
+}
@@ -0,0 +1,173 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Semior001/releaseit/app/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitlabIssues_List(t *testing.T) {
+	svc := newGitlabIssues(t, func(w http.ResponseWriter, r *http.Request) {
+		var resp any
+		switch r.URL.Path {
+		case "/api/v4/projects/projectID/issues/1":
+			resp = J{
+				"id":          101,
+				"iid":         1,
+				"title":       "title",
+				"description": "description",
+				"web_url":     "https://gitlab.com/group/project/-/issues/1",
+				"closed_at":   time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+				"issue_type":  "issue",
+				"author":      J{"username": "author"},
+				"assignees":   []J{{"username": "assignee"}, {"username": "another"}},
+				"milestone":   J{"id": 10, "iid": 3, "title": "v1.0.0", "state": "active"},
+				"epic": J{
+					"iid":      5,
+					"group_id": 7,
+					"title":    "epic",
+					"web_url":  "https://gitlab.com/groups/group/-/epics/5",
+				},
+			}
+		case "/api/v4/projects/projectID/issues/2":
+			resp = J{
+				"id":         102,
+				"iid":        2,
+				"title":      "title-2",
+				"web_url":    "https://gitlab.com/group/project/-/issues/2",
+				"issue_type": "task",
+				"author":     J{"username": "author"},
+				"assignee":   J{"username": "assignee"},
+				"milestone": J{
+					"id":         10,
+					"iid":        3,
+					"title":      "v1.0.0",
+					"web_url":    "https://gitlab.com/group/project/-/milestones/3",
+					"state":      "closed",
+					"updated_at": time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+				},
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			resp = J{"message": "404 Not found"}
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(resp))
+	})
+
+	tickets, err := svc.List(context.Background(), []string{"#1", "2", "#3"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []task.Ticket{
+		{
+			ID:       "#1",
+			ParentID: "&5",
+			URL:      "https://gitlab.com/group/project/-/issues/1",
+			Name:     "title",
+			Body:     "description",
+			ClosedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			Author:   task.User{Username: "author"},
+			Assignee: task.User{Username: "assignee"},
+			Type:     task.TypeTask,
+			TypeRaw:  "issue",
+		},
+		{
+			ID:       "#2",
+			ParentID: "%10",
+			URL:      "https://gitlab.com/group/project/-/issues/2",
+			Name:     "title-2",
+			Author:   task.User{Username: "author"},
+			Assignee: task.User{Username: "assignee"},
+			Type:     task.TypeSubtask,
+			TypeRaw:  "task",
+		},
+	}, utcTimes(tickets))
+
+	parents, err := svc.List(context.Background(), []string{"&5", "%10"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []task.Ticket{
+		{
+			ID:      "&5",
+			URL:     "https://gitlab.com/groups/group/-/epics/5",
+			Name:    "epic",
+			Type:    task.TypeEpic,
+			TypeRaw: "epic",
+		},
+		{
+			ID:       "%10",
+			URL:      "https://gitlab.com/group/project/-/milestones/3",
+			Name:     "v1.0.0",
+			ClosedAt: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+			Type:     task.TypeEpic,
+			TypeRaw:  "milestone",
+		},
+	}, utcTimes(parents), "parents must be taken from the issues")
+}
+
+func TestGitlabIssues_List_WithoutRetries(t *testing.T) {
+	calls := 0
+	svc := newGitlabIssues(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v4/projects/projectID/issues/1", r.URL.Path)
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	_, err := svc.List(context.Background(), []string{"#1"})
+	require.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestGitlabIssues_Get(t *testing.T) {
+	t.Run("milestone", func(t *testing.T) {
+		svc := newGitlabIssues(t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/api/v4/projects/projectID/milestones/10", r.URL.Path)
+			require.NoError(t, json.NewEncoder(w).Encode(J{"id": 10, "iid": 3, "title": "v1.0.0", "state": "active"}))
+		})
+
+		ticket, err := svc.Get(context.Background(), "%10")
+		require.NoError(t, err)
+		assert.Equal(t, task.Ticket{ID: "%10", Name: "v1.0.0", Type: task.TypeEpic, TypeRaw: "milestone"}, ticket)
+	})
+
+	t.Run("unknown epic", func(t *testing.T) {
+		svc := newGitlabIssues(t, func(w http.ResponseWriter, r *http.Request) {
+			require.FailNow(t, "unexpected request", r.URL.Path)
+		})
+
+		_, err := svc.Get(context.Background(), "&5")
+		assert.EqualError(t, err, "epic &5 is not referenced by any issue: 404 Not Found")
+	})
+}
+
+func newGitlabIssues(t *testing.T, h http.HandlerFunc) *GitlabIssues {
+	t.Helper()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "token", r.Header.Get("PRIVATE-TOKEN"))
+
+		if r.URL.Path == "/api/v4/projects/projectID" {
+			require.NoError(t, json.NewEncoder(w).Encode(J{"id": 1}))
+			return
+		}
+
+		h(w, r)
+	}))
+	t.Cleanup(ts.Close)
+
+	svc, err := NewGitlabIssues(context.Background(), GitlabIssuesParams{
+		Token:      "token",
+		BaseURL:    ts.URL,
+		ProjectID:  "projectID",
+		HTTPClient: *ts.Client(),
+	})
+	require.NoError(t, err)
+
+	return svc
+}
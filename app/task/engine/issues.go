@@ -0,0 +1,64 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Semior001/releaseit/app/task"
+)
+
+// issueRef is a reference to the issue (#42), milestone (%3) or epic (&5)
+// of the repository, in the format of gitlab references.
+type issueRef struct {
+	kind   byte
+	number int64
+}
+
+// parseIssueRef parses the reference, the number without prefix
+// is considered to be a reference to the issue.
+func parseIssueRef(s string) (issueRef, error) {
+	ref := issueRef{kind: '#'}
+
+	s = strings.TrimSpace(s)
+	if s != "" && strings.IndexByte("#%&", s[0]) >= 0 {
+		ref.kind, s = s[0], s[1:]
+	}
+
+	var err error
+	if ref.number, err = strconv.ParseInt(s, 10, 64); err != nil {
+		return issueRef{}, fmt.Errorf("parse number of %q: %w", s, err)
+	}
+
+	return ref, nil
+}
+
+func (r issueRef) String() string { return fmt.Sprintf("%c%d", r.kind, r.number) }
+
+// parentsCache keeps tickets of milestones and epics, which were met
+// in issues, to avoid requesting them once again, when they're listed
+// as parents of the issues.
+type parentsCache struct {
+	mu      sync.Mutex
+	tickets map[string]task.Ticket
+}
+
+func (c *parentsCache) put(t task.Ticket) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.tickets == nil {
+		c.tickets = map[string]task.Ticket{}
+	}
+
+	c.tickets[t.ID] = t
+}
+
+func (c *parentsCache) get(id string) (task.Ticket, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t, ok := c.tickets[id]
+	return t, ok
+}
@@ -3,6 +3,7 @@ package engine
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -20,6 +21,16 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
+const (
+	// jiraBatchSize is the maximum number of keys in a single query,
+	// to keep the url of the request within limits.
+	jiraBatchSize = 50
+	// jiraPageSize is the number of issues requested per page.
+	jiraPageSize = 50
+	// jiraConcurrency is the maximum number of batches requested at once.
+	jiraConcurrency = 5
+)
+
 // Jira is a Jira task tracker engine.
 type Jira struct {
 	httpCl          *http.Client
@@ -34,7 +45,9 @@ type Jira struct {
 // JiraParams is a set of parameters for Jira engine.
 type JiraParams struct {
 	BaseURL    string
-	Token      string
+	Token      string // personal access token for jira server, api token for jira cloud
+	Email      string // email of the api token owner, used only with jira cloud
+	Cloud      bool   // use basic auth and v3 api of jira cloud
 	HTTPClient http.Client
 	Enricher   struct {
 		LoadWatchers bool
@@ -43,8 +56,13 @@ type JiraParams struct {
 
 // NewJira creates a new Jira engine.
 func NewJira(ctx context.Context, params JiraParams) (*Jira, error) {
+	auth := middleware.Header("Authorization", "Bearer "+params.Token)
+	if params.Cloud {
+		auth = middleware.BasicAuth(params.Email, params.Token)
+	}
+
 	rq := requester.New(params.HTTPClient,
-		middleware.Header("Authorization", "Bearer "+params.Token),
+		auth,
 		logger.New(logger.Func(log.Printf), logger.Prefix("[DEBUG]")).Middleware,
 	)
 
@@ -66,35 +84,104 @@ func NewJira(ctx context.Context, params JiraParams) (*Jira, error) {
 }
 
 // List lists tasks from the provided project by their IDs.
+// Keys are requested in batches, non-existing ones are omitted.
 func (j *Jira) List(ctx context.Context, keys []string) ([]task.Ticket, error) {
-	query := fmt.Sprintf("key in (%s)", strings.Join(keys, ","))
+	batches := lo.Chunk(keys, jiraBatchSize)
+	results := make([][]jira.Issue, len(batches))
+
+	ewg, ectx := errgroup.WithContext(ctx)
+	ewg.SetLimit(jiraConcurrency)
+	for idx, batch := range batches {
+		idx, batch := idx, batch
+		ewg.Go(func() (err error) {
+			if results[idx], err = j.listBatch(ectx, batch); err != nil {
+				return fmt.Errorf("jira returned error: %w", err)
+			}
+			return nil
+		})
+	}
 
-	// jira returns error on non-existing tickets, if key is in uppercase,
-	// so we need to lowercase it to avoid the error.
-	// ref: https://jira.atlassian.com/browse/JRASERVER-23287
-	query = strings.ToLower(query)
-	issues, _, err := j.cl.Issue.SearchWithContext(ctx, query, nil)
-	if err != nil {
-		if j.isNotFoundErr(err) {
-			return nil, nil
-		}
-		return nil, fmt.Errorf("jira returned error: %w", err)
+	if err := ewg.Wait(); err != nil {
+		return nil, err
 	}
 
-	tickets := lo.Map(issues, func(item jira.Issue, _ int) task.Ticket {
+	tickets := lo.Map(lo.Flatten(results), func(item jira.Issue, _ int) task.Ticket {
 		return j.transformIssue(item)
 	})
 
-	if tickets, err = j.enrich(ctx, tickets); err != nil {
+	tickets, err := j.enrich(ctx, tickets)
+	if err != nil {
 		return nil, fmt.Errorf("enrich tickets: %w", err)
 	}
 
 	return tickets, nil
 }
 
+// listBatch searches for issues by keys, if some keys don't exist,
+// the query is retried without them.
+func (j *Jira) listBatch(ctx context.Context, keys []string) ([]jira.Issue, error) {
+	for len(keys) > 0 {
+		query := fmt.Sprintf("key in (%s)", strings.Join(keys, ","))
+
+		// jira returns error on non-existing tickets, if key is in uppercase,
+		// so we need to lowercase it to avoid the error.
+		// ref: https://jira.atlassian.com/browse/JRASERVER-23287
+		query = strings.ToLower(query)
+
+		issues, err := j.search(ctx, query)
+		if err == nil {
+			return issues, nil
+		}
+
+		missing := j.notFoundKeys(err)
+		rest := lo.Reject(keys, func(key string, _ int) bool {
+			return lo.ContainsBy(missing, func(m string) bool { return strings.EqualFold(key, m) })
+		})
+
+		// nothing to exclude, so the retry would fail the same way
+		if len(rest) == len(keys) {
+			return nil, err
+		}
+
+		log.Printf("[DEBUG] jira tickets %v are not found, retrying without them", missing)
+		keys = rest
+	}
+
+	return nil, nil
+}
+
+// search returns all issues, which match the query, page by page.
+func (j *Jira) search(ctx context.Context, query string) ([]jira.Issue, error) {
+	if j.Cloud {
+		return j.searchCloud(ctx, query)
+	}
+
+	var issues []jira.Issue
+	opts := &jira.SearchOptions{MaxResults: jiraPageSize}
+	for {
+		page, resp, err := j.cl.Issue.SearchWithContext(ctx, query, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		issues = append(issues, page...)
+		opts.StartAt += len(page)
+
+		if len(page) == 0 || resp == nil || opts.StartAt >= resp.Total {
+			return issues, nil
+		}
+	}
+}
+
 // Get returns a single task by its ID.
 func (j *Jira) Get(ctx context.Context, key string) (task.Ticket, error) {
-	issue, _, err := j.cl.Issue.GetWithContext(ctx, key, nil)
+	var issue *jira.Issue
+	var err error
+	if j.Cloud {
+		issue, err = j.getCloud(ctx, key)
+	} else {
+		issue, _, err = j.cl.Issue.GetWithContext(ctx, key, nil)
+	}
 	if err != nil {
 		return task.Ticket{}, fmt.Errorf("jira returned error: %w", err)
 	}
@@ -147,7 +234,7 @@ func (j *Jira) enrich(ctx context.Context, tickets []task.Ticket) ([]task.Ticket
 }
 
 func (j *Jira) listWatchers(ctx context.Context, key string) ([]task.User, error) {
-	u := fmt.Sprintf("%s/rest/api/2/issue/%s/watchers", j.baseURL, key)
+	u := fmt.Sprintf("%s/rest/api/%s/issue/%s/watchers", j.baseURL, lo.Ternary(j.Cloud, "3", "2"), key)
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, http.NoBody)
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
@@ -254,17 +341,36 @@ func (j *Jira) transformUser(user *jira.User) task.User {
 		return task.User{}
 	}
 
+	// jira cloud doesn't expose usernames, account id is the only stable identifier there
 	return task.User{
-		Username: user.Name,
+		Username: lo.Ternary(user.Name != "", user.Name, user.AccountID),
 		Email:    user.EmailAddress,
 	}
 }
 
-var notFoundRx = regexp.MustCompile(`An issue with key '(.*)' does not exist for field 'key'.: request failed`)
+var notFoundRx = regexp.MustCompile(`An issue with key '(.*?)' does not exist for field 'key'.`)
 
-func (j *Jira) isNotFoundErr(err error) bool {
+// notFoundKeys returns keys of the non-existing issues, which caused the error.
+func (j *Jira) notFoundKeys(err error) []string {
 	if err == nil {
-		return false
+		return nil
+	}
+
+	// jira reports every missing key, but only the first one is in the error message
+	msgs := []string{err.Error()}
+	var jerr *jira.Error
+	if errors.As(err, &jerr) {
+		msgs = append(msgs, jerr.ErrorMessages...)
 	}
-	return notFoundRx.MatchString(err.Error())
+
+	var keys []string
+	for _, msg := range msgs {
+		for _, match := range notFoundRx.FindAllStringSubmatch(msg, -1) {
+			if !lo.Contains(keys, match[1]) {
+				keys = append(keys, match[1])
+			}
+		}
+	}
+
+	return keys
 }
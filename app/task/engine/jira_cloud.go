@@ -0,0 +1,260 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// searchCloud searches for issues via the v3 api of jira cloud,
+// going through all pages of the result.
+func (j *Jira) searchCloud(ctx context.Context, jql string) ([]jira.Issue, error) {
+	var issues []jira.Issue
+
+	q := url.Values{"jql": {jql}, "fields": {"*all"}}
+	for {
+		var page struct {
+			Issues        []jiraCloudIssue `json:"issues"`
+			NextPageToken string           `json:"nextPageToken"`
+		}
+
+		if err := j.doCloud(ctx, "rest/api/3/search/jql?"+q.Encode(), &page); err != nil {
+			return nil, err
+		}
+
+		for _, issue := range page.Issues {
+			issues = append(issues, jira.Issue(issue))
+		}
+
+		if page.NextPageToken == "" {
+			return issues, nil
+		}
+
+		q.Set("nextPageToken", page.NextPageToken)
+	}
+}
+
+// getCloud returns a single issue via the v3 api of jira cloud.
+func (j *Jira) getCloud(ctx context.Context, key string) (*jira.Issue, error) {
+	var issue jiraCloudIssue
+	if err := j.doCloud(ctx, "rest/api/3/issue/"+url.PathEscape(key), &issue); err != nil {
+		return nil, err
+	}
+
+	return (*jira.Issue)(&issue), nil
+}
+
+func (j *Jira) doCloud(ctx context.Context, path string, v any) error {
+	req, err := j.cl.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := j.cl.Do(req, v)
+	if err != nil {
+		// the same error as in the client, to keep messages of jira intact
+		return jira.NewJiraError(resp, err)
+	}
+
+	return nil
+}
+
+// jiraCloudIssue is an issue of the v3 api, its description
+// is described in Atlassian Document Format, which is converted to markdown.
+type jiraCloudIssue jira.Issue
+
+// UnmarshalJSON converts the description to markdown and unmarshals
+// the rest of the issue as is.
+func (i *jiraCloudIssue) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	var doc adfNode
+	if rawFields, ok := raw["fields"]; ok {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(rawFields, &fields); err != nil {
+			return fmt.Errorf("unmarshal fields: %w", err)
+		}
+
+		if desc, ok := fields["description"]; ok {
+			if err := json.Unmarshal(desc, &doc); err != nil {
+				return fmt.Errorf("unmarshal description: %w", err)
+			}
+		}
+
+		delete(fields, "description")
+
+		b, err := json.Marshal(fields)
+		if err != nil {
+			return fmt.Errorf("marshal fields: %w", err)
+		}
+		raw["fields"] = b
+	}
+
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return fmt.Errorf("marshal issue: %w", err)
+	}
+
+	if err = json.Unmarshal(b, (*jira.Issue)(i)); err != nil {
+		return err
+	}
+
+	if i.Fields != nil {
+		i.Fields.Description = doc.Markdown()
+	}
+
+	return nil
+}
+
+// adfNode is a node of the document in Atlassian Document Format.
+// Marks are represented as nodes too, as they have type and attributes.
+// ref: https://developer.atlassian.com/cloud/jira/platform/apis/document/structure/
+type adfNode struct {
+	Type    string         `json:"type"`
+	Text    string         `json:"text"`
+	Attrs   map[string]any `json:"attrs"`
+	Marks   []adfNode      `json:"marks"`
+	Content []adfNode      `json:"content"`
+}
+
+// Markdown renders the document as markdown, unsupported nodes
+// are rendered by their content.
+func (n adfNode) Markdown() string { return strings.TrimSpace(n.block()) }
+
+func (n adfNode) block() string {
+	switch n.Type {
+	case "paragraph":
+		return n.inline()
+	case "heading":
+		return strings.Repeat("#", n.intAttr("level", 1)) + " " + n.inline()
+	case "bulletList", "orderedList":
+		return n.list()
+	case "codeBlock":
+		return "```" + n.strAttr("language") + "\n" + n.inline() + "\n```"
+	case "blockquote", "panel":
+		return "> " + strings.ReplaceAll(n.blocks("\n\n"), "\n", "\n> ")
+	case "rule":
+		return "---"
+	case "table":
+		return n.table()
+	case "mediaSingle", "mediaGroup", "media":
+		return ""
+	}
+
+	if len(n.Content) == 0 {
+		return n.inlineNode()
+	}
+
+	return n.blocks("\n\n")
+}
+
+func (n adfNode) blocks(sep string) string {
+	var parts []string
+	for _, child := range n.Content {
+		if s := child.block(); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, sep)
+}
+
+func (n adfNode) inline() string {
+	sb := strings.Builder{}
+	for _, child := range n.Content {
+		sb.WriteString(child.inlineNode())
+	}
+	return sb.String()
+}
+
+func (n adfNode) inlineNode() string {
+	switch n.Type {
+	case "text":
+		return n.marked(n.Text)
+	case "hardBreak":
+		return "\n"
+	case "mention", "status":
+		return n.strAttr("text")
+	case "emoji":
+		if text := n.strAttr("text"); text != "" {
+			return text
+		}
+		return n.strAttr("shortName")
+	case "inlineCard":
+		return n.strAttr("url")
+	}
+
+	return n.inline()
+}
+
+func (n adfNode) marked(s string) string {
+	for _, mark := range n.Marks {
+		switch mark.Type {
+		case "code":
+			s = "`" + s + "`"
+		case "strong":
+			s = "**" + s + "**"
+		case "em":
+			s = "_" + s + "_"
+		case "strike":
+			s = "~~" + s + "~~"
+		case "link":
+			s = "[" + s + "](" + mark.strAttr("href") + ")"
+		}
+	}
+	return s
+}
+
+func (n adfNode) list() string {
+	items := make([]string, 0, len(n.Content))
+	for idx, item := range n.Content {
+		marker := "- "
+		if n.Type == "orderedList" {
+			marker = fmt.Sprintf("%d. ", n.intAttr("order", 1)+idx)
+		}
+
+		// nested blocks of the item are aligned with its first line
+		indent := "\n" + strings.Repeat(" ", len(marker))
+		items = append(items, marker+strings.ReplaceAll(item.blocks("\n"), "\n", indent))
+	}
+	return strings.Join(items, "\n")
+}
+
+func (n adfNode) table() string {
+	rows := make([]string, 0, len(n.Content)+1)
+	for idx, row := range n.Content {
+		cells := make([]string, 0, len(row.Content))
+		for _, cell := range row.Content {
+			text := strings.ReplaceAll(cell.blocks(" "), "|", `\|`)
+			cells = append(cells, strings.ReplaceAll(text, "\n", " "))
+		}
+
+		rows = append(rows, "| "+strings.Join(cells, " | ")+" |")
+
+		// markdown requires the header, so the first row is always taken as one
+		if idx == 0 {
+			rows = append(rows, "|"+strings.Repeat(" --- |", len(cells)))
+		}
+	}
+	return strings.Join(rows, "\n")
+}
+
+func (n adfNode) strAttr(key string) string {
+	s, _ := n.Attrs[key].(string)
+	return s
+}
+
+func (n adfNode) intAttr(key string, def int) int {
+	// numbers are decoded as float64 from json
+	if f, ok := n.Attrs[key].(float64); ok {
+		return int(f)
+	}
+	return def
+}
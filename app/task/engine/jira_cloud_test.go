@@ -0,0 +1,224 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Semior001/releaseit/app/task"
+	"github.com/andygrunwald/go-jira"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJira_Cloud(t *testing.T) {
+	description := J{
+		"type": "doc",
+		"content": []J{{
+			"type": "paragraph",
+			"content": []J{
+				{"type": "text", "text": "some "},
+				{"type": "text", "text": "bold", "marks": []J{{"type": "strong"}}},
+				{"type": "text", "text": " text"},
+			},
+		}},
+	}
+
+	t.Run("list", func(t *testing.T) {
+		calls := 0
+		j := newJiraCloud(t, func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			require.Equal(t, "/rest/api/3/search/jql", r.URL.Path)
+			assert.Equal(t, "key in (key-1,key-2)", r.URL.Query().Get("jql"))
+			assert.Equal(t, "*all", r.URL.Query().Get("fields"))
+
+			resp := J{"issues": []J{{
+				"key": "KEY-1",
+				"fields": J{
+					"summary":     "summary",
+					"description": description,
+					"creator":     J{"accountId": "creator-id", "emailAddress": "creator@jira.com"},
+					"assignee":    J{"accountId": "assignee-id"},
+				},
+			}}, "nextPageToken": "next"}
+
+			if r.URL.Query().Get("nextPageToken") == "next" {
+				resp = J{"issues": []J{{
+					"key":    "KEY-2",
+					"fields": J{"summary": "summary-1", "description": nil, "parent": J{"key": "KEY-3"}},
+				}}}
+			}
+
+			require.NoError(t, json.NewEncoder(w).Encode(resp))
+		})
+
+		tickets, err := j.List(context.Background(), []string{"KEY-1", "KEY-2"})
+		require.NoError(t, err)
+		assert.Equal(t, 2, calls, "all pages must be requested")
+
+		assert.Equal(t, []task.Ticket{
+			{
+				ID:       "KEY-1",
+				URL:      fmt.Sprintf("%s/browse/KEY-1", j.baseURL),
+				Name:     "summary",
+				Body:     "some **bold** text",
+				Author:   task.User{Username: "creator-id", Email: "creator@jira.com"},
+				Assignee: task.User{Username: "assignee-id"},
+			},
+			{
+				ID:       "KEY-2",
+				URL:      fmt.Sprintf("%s/browse/KEY-2", j.baseURL),
+				ParentID: "KEY-3",
+				Name:     "summary-1",
+			},
+		}, utcTimes(tickets))
+	})
+
+	t.Run("get", func(t *testing.T) {
+		j := newJiraCloud(t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/rest/api/3/issue/KEY-1", r.URL.Path)
+			require.NoError(t, json.NewEncoder(w).Encode(J{
+				"key":    "KEY-1",
+				"fields": J{"summary": "summary", "description": description},
+			}))
+		})
+
+		ticket, err := j.Get(context.Background(), "KEY-1")
+		require.NoError(t, err)
+		assert.Equal(t, task.Ticket{
+			ID:   "KEY-1",
+			URL:  fmt.Sprintf("%s/browse/KEY-1", j.baseURL),
+			Name: "summary",
+			Body: "some **bold** text",
+		}, utcTimes([]task.Ticket{ticket})[0])
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		j := newJiraCloud(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			require.NoError(t, json.NewEncoder(w).Encode(J{
+				"errorMessages": []string{"An issue with key 'KEY-1' does not exist for field 'key'."},
+			}))
+		})
+
+		tickets, err := j.List(context.Background(), []string{"KEY-1"})
+		require.NoError(t, err)
+		assert.Empty(t, tickets)
+	})
+}
+
+func TestADFNode_Markdown(t *testing.T) {
+	text := func(s string, marks ...J) J { return J{"type": "text", "text": s, "marks": marks} }
+	paragraph := func(content ...J) J { return J{"type": "paragraph", "content": content} }
+
+	tests := []struct {
+		name string
+		doc  []J
+		want string
+	}{
+		{
+			name: "paragraphs and marks",
+			doc: []J{
+				paragraph(text("bold", J{"type": "strong"}), text(" "), text("italic", J{"type": "em"}),
+					J{"type": "hardBreak"}, text("link", J{"type": "link", "attrs": J{"href": "https://example.com"}})),
+				paragraph(text("code", J{"type": "code"}), text(" "), text("strike", J{"type": "strike"})),
+			},
+			want: "**bold** _italic_\n[link](https://example.com)\n\n`code` ~~strike~~",
+		},
+		{
+			name: "heading and code block",
+			doc: []J{
+				{"type": "heading", "attrs": J{"level": 2}, "content": []J{text("title")}},
+				{"type": "codeBlock", "attrs": J{"language": "go"}, "content": []J{text("fmt.Println()")}},
+				{"type": "rule"},
+			},
+			want: "## title\n\n```go\nfmt.Println()\n```\n\n---",
+		},
+		{
+			name: "lists",
+			doc: []J{
+				{"type": "orderedList", "attrs": J{"order": 3}, "content": []J{
+					{"type": "listItem", "content": []J{
+						paragraph(text("first")),
+						{"type": "bulletList", "content": []J{
+							{"type": "listItem", "content": []J{paragraph(text("nested"))}},
+						}},
+					}},
+					{"type": "listItem", "content": []J{paragraph(text("second"))}},
+				}},
+			},
+			want: "3. first\n   - nested\n4. second",
+		},
+		{
+			name: "quote, mention and emoji",
+			doc: []J{
+				{"type": "blockquote", "content": []J{
+					paragraph(J{"type": "mention", "attrs": J{"id": "account-id", "text": "@John"}},
+						text(" "), J{"type": "emoji", "attrs": J{"shortName": ":smile:"}}),
+				}},
+			},
+			want: "> @John :smile:",
+		},
+		{
+			name: "table",
+			doc: []J{
+				{"type": "table", "content": []J{
+					{"type": "tableRow", "content": []J{
+						{"type": "tableHeader", "content": []J{paragraph(text("a"))}},
+						{"type": "tableHeader", "content": []J{paragraph(text("b"))}},
+					}},
+					{"type": "tableRow", "content": []J{
+						{"type": "tableCell", "content": []J{paragraph(text("1|2"))}},
+						{"type": "tableCell", "content": []J{paragraph(text("3"))}},
+					}},
+				}},
+			},
+			want: "| a | b |\n| --- | --- |\n| 1\\|2 | 3 |",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := json.Marshal(J{"type": "doc", "version": 1, "content": tt.doc})
+			require.NoError(t, err)
+
+			var doc adfNode
+			require.NoError(t, json.Unmarshal(b, &doc))
+			assert.Equal(t, tt.want, doc.Markdown())
+		})
+	}
+}
+
+func newJiraCloud(t *testing.T, h http.HandlerFunc) *Jira {
+	t.Helper()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		email, token, ok := r.BasicAuth()
+		require.True(t, ok, "basic auth is not set")
+		require.Equal(t, "user@example.com", email)
+		require.Equal(t, "abacaba", token)
+
+		if r.URL.Path == "/rest/api/2/field" {
+			require.NoError(t, json.NewEncoder(w).Encode([]jira.Field{}))
+			return
+		}
+
+		h(w, r)
+	}))
+	t.Cleanup(ts.Close)
+
+	svc, err := NewJira(context.Background(), JiraParams{
+		BaseURL:    ts.URL,
+		Token:      "abacaba",
+		Email:      "user@example.com",
+		Cloud:      true,
+		HTTPClient: *ts.Client(),
+	})
+	require.NoError(t, err)
+
+	return svc
+}
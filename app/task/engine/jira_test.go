@@ -7,11 +7,14 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/Semior001/releaseit/app/task"
 	"github.com/andygrunwald/go-jira"
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -77,6 +80,98 @@ func TestJira_List(t *testing.T) {
 	}, utcTimes(tickets))
 }
 
+func TestJira_List_batches(t *testing.T) {
+	t.Run("paginated", func(t *testing.T) {
+		j := newJira(t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/rest/api/2/search", r.URL.Path)
+			assert.Equal(t, "50", r.URL.Query().Get("maxResults"))
+
+			key := "KEY-1"
+			if r.URL.Query().Get("startAt") == "1" {
+				key = "KEY-2"
+			}
+
+			err := json.NewEncoder(w).Encode(J{"startAt": 0, "maxResults": 1, "total": 2, "issues": []J{
+				{"key": key, "fields": J{"summary": key}},
+			}})
+			require.NoError(t, err)
+		})
+
+		tickets, err := j.List(context.Background(), []string{"KEY-1", "KEY-2"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"KEY-1", "KEY-2"}, lo.Map(tickets, func(ticket task.Ticket, _ int) string { return ticket.ID }))
+	})
+
+	t.Run("chunked", func(t *testing.T) {
+		keys := make([]string, jiraBatchSize+1)
+		for i := range keys {
+			keys[i] = fmt.Sprintf("KEY-%d", i)
+		}
+
+		var mu sync.Mutex
+		var queries []string
+		j := newJira(t, func(w http.ResponseWriter, r *http.Request) {
+			jql := r.URL.Query().Get("jql")
+
+			mu.Lock()
+			queries = append(queries, jql)
+			mu.Unlock()
+
+			var issues []J
+			for _, key := range strings.Split(strings.TrimSuffix(strings.TrimPrefix(jql, "key in ("), ")"), ",") {
+				issues = append(issues, J{"key": strings.ToUpper(key), "fields": J{}})
+			}
+
+			err := json.NewEncoder(w).Encode(J{"total": len(issues), "issues": issues})
+			require.NoError(t, err)
+		})
+
+		tickets, err := j.List(context.Background(), keys)
+		require.NoError(t, err)
+		assert.Len(t, queries, 2)
+		assert.Equal(t, keys, lo.Map(tickets, func(ticket task.Ticket, _ int) string { return ticket.ID }))
+	})
+
+	t.Run("retries without missing keys", func(t *testing.T) {
+		var jqls []string
+		j := newJira(t, func(w http.ResponseWriter, r *http.Request) {
+			jql := r.URL.Query().Get("jql")
+			jqls = append(jqls, jql)
+
+			if jql != "key in (key-2)" {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusBadRequest)
+				err := json.NewEncoder(w).Encode(J{"errorMessages": []string{
+					"An issue with key 'key-1' does not exist for field 'key'.",
+					"An issue with key 'key-3' does not exist for field 'key'.",
+				}})
+				require.NoError(t, err)
+				return
+			}
+
+			err := json.NewEncoder(w).Encode(J{"total": 1, "issues": []J{{"key": "KEY-2", "fields": J{}}}})
+			require.NoError(t, err)
+		})
+
+		tickets, err := j.List(context.Background(), []string{"KEY-1", "KEY-2", "KEY-3"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"key in (key-1,key-2,key-3)", "key in (key-2)"}, jqls)
+		assert.Equal(t, []string{"KEY-2"}, lo.Map(tickets, func(ticket task.Ticket, _ int) string { return ticket.ID }))
+	})
+
+	t.Run("arbitrary error", func(t *testing.T) {
+		j := newJira(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			err := json.NewEncoder(w).Encode(J{"errorMessages": []string{"something went wrong"}})
+			require.NoError(t, err)
+		})
+
+		_, err := j.List(context.Background(), []string{"KEY-1"})
+		assert.ErrorContains(t, err, "jira returned error: something went wrong")
+	})
+}
+
 func TestJira_Get(t *testing.T) {
 	t.Run("parent explicitly defined", func(t *testing.T) {
 		j := newJira(t, func(w http.ResponseWriter, r *http.Request) {
@@ -284,34 +379,45 @@ func utcTimes(tickets []task.Ticket) []task.Ticket {
 	return tickets
 }
 
-func TestJira_errIsNotFound(t *testing.T) {
+func TestJira_notFoundKeys(t *testing.T) {
 	//goland:noinspection GoErrorStringFormat
 	tests := []struct {
 		name string
 		err  error
-		want bool
+		want []string
 	}{
 		{
 			name: "actual error",
 			err: errors.New("An issue with key 'PROJECT-0' does not exist for field 'key'.: request failed. " +
 				"Please analyze the request body for more details. " +
 				"Status code: 400"),
-			want: true,
+			want: []string{"PROJECT-0"},
+		},
+		{
+			name: "multiple keys",
+			err: &jira.Error{
+				HTTPError: errors.New("request failed"),
+				ErrorMessages: []string{
+					"An issue with key 'project-0' does not exist for field 'key'.",
+					"An issue with key 'project-1' does not exist for field 'key'.",
+				},
+			},
+			want: []string{"project-0", "project-1"},
 		},
 		{
 			name: "arbitrary error",
 			err:  errors.New("arbitrary error"),
-			want: false,
+			want: nil,
 		},
 		{
 			name: "nil",
 			err:  nil,
-			want: false,
+			want: nil,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.Equalf(t, tt.want, (&Jira{}).isNotFoundErr(tt.err), "isNotFoundErr(%v)", tt.err)
+			assert.Equalf(t, tt.want, (&Jira{}).notFoundKeys(tt.err), "notFoundKeys(%v)", tt.err)
 		})
 	}
 }
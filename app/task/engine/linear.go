@@ -0,0 +1,424 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Semior001/releaseit/app/task"
+	"github.com/go-pkgz/requester"
+	"github.com/go-pkgz/requester/middleware"
+	"github.com/go-pkgz/requester/middleware/logger"
+	"github.com/samber/lo"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// linearBatchSize is the maximum number of tickets in a single query,
+	// to keep the complexity of the query within limits of the API.
+	linearBatchSize = 50
+	// linearConcurrency is the maximum number of batches requested at once.
+	linearConcurrency = 5
+)
+
+// Linear is a Linear task tracker engine. Issues are referenced by their
+// identifiers, e.g. ENG-123, while any other ID is considered to be an ID
+// of the project, which is returned as an epic.
+type Linear struct {
+	cl *http.Client
+	LinearParams
+}
+
+// LinearParams is a set of parameters for Linear engine.
+type LinearParams struct {
+	BaseURL    string // https://api.linear.app by default
+	Token      string // personal API key, OAuth tokens must be prefixed with "Bearer "
+	HTTPClient http.Client
+}
+
+// NewLinear creates a new Linear engine and checks the connection to it.
+func NewLinear(ctx context.Context, params LinearParams) (*Linear, error) {
+	if params.BaseURL == "" {
+		params.BaseURL = "https://api.linear.app"
+	}
+
+	params.BaseURL = strings.TrimSuffix(params.BaseURL, "/")
+
+	rq := requester.New(params.HTTPClient,
+		middleware.Header("Authorization", params.Token),
+		middleware.JSON,
+		logger.New(logger.Func(log.Printf), logger.Prefix("[DEBUG]")).Middleware,
+	)
+
+	svc := &Linear{cl: rq.Client(), LinearParams: params}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultSetupTimeout)
+	defer cancel()
+
+	if _, err := svc.do(ctx, "query Viewer { viewer { id } }", nil); err != nil {
+		return nil, fmt.Errorf("ping linear: %w", err)
+	}
+
+	return svc, nil
+}
+
+// List lists issues and projects by their IDs. IDs are requested
+// in batches, non-existing ones are omitted.
+func (l *Linear) List(ctx context.Context, ids []string) ([]task.Ticket, error) {
+	batches := lo.Chunk(ids, linearBatchSize)
+	results := make([][]task.Ticket, len(batches))
+
+	ewg, ectx := errgroup.WithContext(ctx)
+	ewg.SetLimit(linearConcurrency)
+	for idx, batch := range batches {
+		idx, batch := idx, batch
+		ewg.Go(func() (err error) {
+			results[idx], err = l.listBatch(ectx, batch)
+			return err
+		})
+	}
+
+	if err := ewg.Wait(); err != nil {
+		return nil, err
+	}
+
+	return lo.Flatten(results), nil
+}
+
+// listBatch requests all tickets of the batch in a single query.
+// As the fields of the query are non-null, the single missing ticket
+// nulls the whole data, so the query is retried without missing tickets.
+func (l *Linear) listBatch(ctx context.Context, ids []string) ([]task.Ticket, error) {
+	for len(ids) > 0 {
+		resp, err := l.fetch(ctx, ids)
+		if err != nil {
+			return nil, fmt.Errorf("linear returned error: %w", err)
+		}
+
+		if resp.Data == nil {
+			missing := resp.failedIDs(ids)
+			rest := lo.Without(ids, missing...)
+
+			// nothing to exclude, so the retry would fail the same way
+			if len(rest) == len(ids) {
+				return nil, fmt.Errorf("linear returned error: %s", resp.errorAt(""))
+			}
+
+			log.Printf("[DEBUG] linear tickets %v are not found, retrying without them", missing)
+			ids = rest
+			continue
+		}
+
+		var tickets []task.Ticket
+		for idx, id := range ids {
+			ticket, ok, err := l.transform(id, resp.Data[linearAlias(idx)])
+			if err != nil {
+				return nil, fmt.Errorf("transform %s: %w", id, err)
+			}
+
+			if !ok {
+				log.Printf("[DEBUG] linear ticket %s is not found: %s", id, resp.errorAt(linearAlias(idx)))
+				continue
+			}
+
+			tickets = append(tickets, ticket)
+		}
+
+		return tickets, nil
+	}
+
+	return nil, nil
+}
+
+// Get returns a single issue or project by its ID.
+func (l *Linear) Get(ctx context.Context, id string) (task.Ticket, error) {
+	resp, err := l.fetch(ctx, []string{id})
+	if err != nil {
+		return task.Ticket{}, fmt.Errorf("linear returned error: %w", err)
+	}
+
+	ticket, ok, err := l.transform(id, resp.Data[linearAlias(0)])
+	if err != nil {
+		return task.Ticket{}, fmt.Errorf("transform %s: %w", id, err)
+	}
+
+	if !ok {
+		return task.Ticket{}, fmt.Errorf("linear returned error: %s", resp.errorAt(linearAlias(0)))
+	}
+
+	return ticket, nil
+}
+
+const (
+	linearIssueFragment = `fragment issue on Issue {
+	identifier title description url completedAt canceledAt
+	state { name type }
+	labels { nodes { name } }
+	creator { displayName email }
+	assignee { displayName email }
+	parent { identifier }
+	project { id }
+}`
+	linearProjectFragment = `fragment project on Project {
+	id name description url completedAt canceledAt
+	creator { displayName email }
+	lead { displayName email }
+}`
+)
+
+// linearIdentifierRx matches identifiers of issues, e.g. ENG-123.
+var linearIdentifierRx = regexp.MustCompile(`^[A-Za-z0-9]+-\d+$`)
+
+func linearAlias(idx int) string { return fmt.Sprintf("t%d", idx) }
+
+type linearResponse struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []linearError              `json:"errors"`
+}
+
+type linearError struct {
+	Message string `json:"message"`
+	Path    []any  `json:"path"`
+}
+
+// errorAt returns the error message for the aliased field,
+// or the first error, if the alias is empty.
+func (r linearResponse) errorAt(alias string) string {
+	for _, e := range r.Errors {
+		if len(e.Path) > 0 && (alias == "" || e.Path[0] == alias) {
+			return e.Message
+		}
+	}
+	return "entity not found"
+}
+
+// failedIDs returns ids, which fields are named in the paths of errors.
+func (r linearResponse) failedIDs(ids []string) []string {
+	var res []string
+	for idx, id := range ids {
+		alias := linearAlias(idx)
+		if lo.ContainsBy(r.Errors, func(e linearError) bool { return len(e.Path) > 0 && e.Path[0] == alias }) {
+			res = append(res, id)
+		}
+	}
+	return res
+}
+
+// fetch requests all tickets in a single query, each ticket is aliased
+// by its index in the list of ids.
+func (l *Linear) fetch(ctx context.Context, ids []string) (linearResponse, error) {
+	var (
+		vars      []string
+		fields    []string
+		fragments []string
+	)
+
+	variables := make(map[string]any, len(ids))
+	for idx, id := range ids {
+		alias := linearAlias(idx)
+		variables[alias] = id
+		vars = append(vars, fmt.Sprintf("$%s: String!", alias))
+
+		if linearIdentifierRx.MatchString(id) {
+			fields = append(fields, fmt.Sprintf("%s: issue(id: $%s) { ...issue }", alias, alias))
+			fragments = append(fragments, linearIssueFragment)
+			continue
+		}
+
+		fields = append(fields, fmt.Sprintf("%s: project(id: $%s) { ...project }", alias, alias))
+		fragments = append(fragments, linearProjectFragment)
+	}
+
+	// graphql fails on the unused fragments, so only required ones are added
+	query := fmt.Sprintf("query Tickets(%s) {\n%s\n}\n%s",
+		strings.Join(vars, ", "), strings.Join(fields, "\n"), strings.Join(lo.Uniq(fragments), "\n"))
+
+	return l.do(ctx, query, variables)
+}
+
+// do makes a graphql request, errors of the particular fields
+// are returned in the response.
+func (l *Linear) do(ctx context.Context, query string, variables map[string]any) (linearResponse, error) {
+	b, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		return linearResponse{}, fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, l.BaseURL+"/graphql", bytes.NewReader(b))
+	if err != nil {
+		return linearResponse{}, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := l.cl.Do(req)
+	if err != nil {
+		return linearResponse{}, fmt.Errorf("do request: %w", err)
+	}
+	defer func() {
+		if err = resp.Body.Close(); err != nil {
+			log.Printf("[WARN] can't close response body, %s", err)
+		}
+	}()
+
+	// linear responds with 400 on invalid queries, but still
+	// describes errors in the body
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusBadRequest {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return linearResponse{}, fmt.Errorf("unexpected status code: %d, message: %q", resp.StatusCode, msg)
+	}
+
+	var res linearResponse
+	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return linearResponse{}, fmt.Errorf("decode response: %w", err)
+	}
+
+	// errors, which are not bound to any ticket, mean that the query failed at all
+	for _, e := range res.Errors {
+		if len(e.Path) == 0 {
+			return linearResponse{}, errors.New(e.Message)
+		}
+	}
+
+	return res, nil
+}
+
+type linearUser struct {
+	DisplayName string `json:"displayName"`
+	Email       string `json:"email"`
+}
+
+type linearIssue struct {
+	Identifier  string     `json:"identifier"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	URL         string     `json:"url"`
+	CompletedAt *time.Time `json:"completedAt"`
+	CanceledAt  *time.Time `json:"canceledAt"`
+	State       struct {
+		Name string `json:"name"`
+		Type string `json:"type"` // triage, backlog, unstarted, started, completed or canceled
+	} `json:"state"`
+	Labels struct {
+		Nodes []struct {
+			Name string `json:"name"`
+		} `json:"nodes"`
+	} `json:"labels"`
+	Creator  *linearUser `json:"creator"`
+	Assignee *linearUser `json:"assignee"`
+	Parent   *struct {
+		Identifier string `json:"identifier"`
+	} `json:"parent"`
+	Project *struct {
+		ID string `json:"id"`
+	} `json:"project"`
+}
+
+type linearProject struct {
+	ID          string      `json:"id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	URL         string      `json:"url"`
+	CompletedAt *time.Time  `json:"completedAt"`
+	CanceledAt  *time.Time  `json:"canceledAt"`
+	Creator     *linearUser `json:"creator"`
+	Lead        *linearUser `json:"lead"`
+}
+
+// transform converts the raw issue or project to the ticket,
+// returns false, if the ticket is not found.
+func (l *Linear) transform(id string, raw json.RawMessage) (task.Ticket, bool, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return task.Ticket{}, false, nil
+	}
+
+	if !linearIdentifierRx.MatchString(id) {
+		var project linearProject
+		if err := json.Unmarshal(raw, &project); err != nil {
+			return task.Ticket{}, false, fmt.Errorf("unmarshal project: %w", err)
+		}
+		return l.transformProject(project), true, nil
+	}
+
+	var issue linearIssue
+	if err := json.Unmarshal(raw, &issue); err != nil {
+		return task.Ticket{}, false, fmt.Errorf("unmarshal issue: %w", err)
+	}
+
+	return l.transformIssue(issue), true, nil
+}
+
+var linearLabelTypeMapping = map[string]task.Type{
+	"epic": task.TypeEpic,
+	"bug":  task.TypeTask, "feature": task.TypeTask, "improvement": task.TypeTask, "task": task.TypeTask,
+	"sub-task": task.TypeSubtask, "subtask": task.TypeSubtask,
+}
+
+func (l *Linear) transformIssue(issue linearIssue) task.Ticket {
+	ticket := task.Ticket{
+		ID:       issue.Identifier,
+		URL:      issue.URL,
+		Name:     issue.Title,
+		Body:     issue.Description,
+		Author:   l.transformUser(issue.Creator),
+		Assignee: l.transformUser(issue.Assignee),
+		Type:     task.TypeTask,
+		TypeRaw:  issue.State.Name,
+	}
+
+	// linear doesn't have issue types, teams use labels instead,
+	// if there is no such label, the raw type is the state of the issue
+	for _, label := range issue.Labels.Nodes {
+		if tp, ok := linearLabelTypeMapping[strings.ToLower(label.Name)]; ok {
+			ticket.Type, ticket.TypeRaw = tp, label.Name
+			break
+		}
+	}
+
+	switch {
+	case issue.Parent != nil:
+		ticket.ParentID = issue.Parent.Identifier
+		if ticket.Type == task.TypeTask {
+			ticket.Type = task.TypeSubtask
+		}
+	case issue.Project != nil:
+		ticket.ParentID = issue.Project.ID
+	}
+
+	switch issue.State.Type {
+	case "completed":
+		ticket.ClosedAt = lo.FromPtr(issue.CompletedAt)
+	case "canceled":
+		ticket.ClosedAt = lo.FromPtr(issue.CanceledAt)
+	}
+
+	return ticket
+}
+
+func (l *Linear) transformProject(project linearProject) task.Ticket {
+	return task.Ticket{
+		ID:       project.ID,
+		URL:      project.URL,
+		Name:     project.Name,
+		Body:     project.Description,
+		ClosedAt: lo.FromPtr(lo.Ternary(project.CompletedAt != nil, project.CompletedAt, project.CanceledAt)),
+		Author:   l.transformUser(project.Creator),
+		Assignee: l.transformUser(project.Lead),
+		Type:     task.TypeEpic,
+		TypeRaw:  "project",
+	}
+}
+
+func (l *Linear) transformUser(user *linearUser) task.User {
+	if user == nil {
+		return task.User{}
+	}
+
+	return task.User{Username: user.DisplayName, Email: user.Email}
+}
@@ -0,0 +1,249 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Semior001/releaseit/app/task"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinear_List(t *testing.T) {
+	entities := map[string]J{
+		"ENG-1": {
+			"identifier":  "ENG-1",
+			"title":       "title",
+			"description": "description",
+			"url":         "https://linear.app/org/issue/ENG-1",
+			"completedAt": time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			"state":       J{"name": "Done", "type": "completed"},
+			"labels":      J{"nodes": []J{{"name": "backend"}, {"name": "Bug"}}},
+			"creator":     J{"displayName": "creator", "email": "creator@example.com"},
+			"assignee":    J{"displayName": "assignee", "email": "assignee@example.com"},
+			"project":     J{"id": "project-id"},
+		},
+		"ENG-2": {
+			"identifier": "ENG-2",
+			"title":      "title-1",
+			"url":        "https://linear.app/org/issue/ENG-2",
+			"state":      J{"name": "In Progress", "type": "started"},
+			"labels":     J{"nodes": []J{}},
+			"creator":    J{"displayName": "creator", "email": "creator@example.com"},
+			"parent":     J{"identifier": "ENG-1"},
+			"project":    J{"id": "project-id"},
+		},
+		"project-id": {
+			"id":          "project-id",
+			"name":        "project",
+			"description": "project description",
+			"url":         "https://linear.app/org/project/project-id",
+			"lead":        J{"displayName": "lead", "email": "lead@example.com"},
+		},
+	}
+
+	calls := 0
+	l := newLinear(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		var req struct {
+			Query     string            `json:"query"`
+			Variables map[string]string `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		if calls == 1 {
+			assert.Equal(t, map[string]string{"t0": "ENG-1", "t1": "ENG-2", "t2": "ENG-3", "t3": "project-id"}, req.Variables)
+			assert.Contains(t, req.Query, "query Tickets($t0: String!, $t1: String!, $t2: String!, $t3: String!)")
+			assert.Contains(t, req.Query, "t0: issue(id: $t0) { ...issue }")
+			assert.Contains(t, req.Query, "t3: project(id: $t3) { ...project }")
+			assert.Equal(t, 1, strings.Count(req.Query, "fragment issue on Issue"))
+		}
+
+		// fields are non-null, so the single missing entity nulls the whole data
+		data, errs := J{}, []J{}
+		for alias, id := range req.Variables {
+			if entity, ok := entities[id]; ok {
+				data[alias] = entity
+				continue
+			}
+			errs = append(errs, J{"message": "Entity not found: Issue", "path": []string{alias}})
+		}
+
+		if len(errs) > 0 {
+			require.NoError(t, json.NewEncoder(w).Encode(J{"data": nil, "errors": errs}))
+			return
+		}
+
+		require.NoError(t, json.NewEncoder(w).Encode(J{"data": data}))
+	})
+
+	tickets, err := l.List(context.Background(), []string{"ENG-1", "ENG-2", "ENG-3", "project-id"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls, "the batch must be retried without the missing ticket")
+
+	assert.Equal(t, []task.Ticket{
+		{
+			ID:       "ENG-1",
+			ParentID: "project-id",
+			URL:      "https://linear.app/org/issue/ENG-1",
+			Name:     "title",
+			Body:     "description",
+			ClosedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			Author:   task.User{Username: "creator", Email: "creator@example.com"},
+			Assignee: task.User{Username: "assignee", Email: "assignee@example.com"},
+			Type:     task.TypeTask,
+			TypeRaw:  "Bug",
+		},
+		{
+			ID:       "ENG-2",
+			ParentID: "ENG-1",
+			URL:      "https://linear.app/org/issue/ENG-2",
+			Name:     "title-1",
+			Author:   task.User{Username: "creator", Email: "creator@example.com"},
+			Type:     task.TypeSubtask,
+			TypeRaw:  "In Progress",
+		},
+		{
+			ID:       "project-id",
+			URL:      "https://linear.app/org/project/project-id",
+			Name:     "project",
+			Body:     "project description",
+			Assignee: task.User{Username: "lead", Email: "lead@example.com"},
+			Type:     task.TypeEpic,
+			TypeRaw:  "project",
+		},
+	}, utcTimes(tickets))
+}
+
+func TestLinear_List_batches(t *testing.T) {
+	ids := make([]string, linearBatchSize+1)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("ENG-%d", i)
+	}
+
+	var mu sync.Mutex
+	var sizes []int
+	l := newLinear(t, func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Variables map[string]string `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		mu.Lock()
+		sizes = append(sizes, len(req.Variables))
+		mu.Unlock()
+
+		data := J{}
+		for alias, id := range req.Variables {
+			data[alias] = J{"identifier": id, "state": J{"name": "Todo", "type": "unstarted"}}
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(J{"data": data}))
+	})
+
+	tickets, err := l.List(context.Background(), ids)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int{linearBatchSize, 1}, sizes)
+	assert.Equal(t, ids, lo.Map(tickets, func(ticket task.Ticket, _ int) string { return ticket.ID }))
+}
+
+func TestLinear_Get(t *testing.T) {
+	t.Run("found", func(t *testing.T) {
+		l := newLinear(t, func(w http.ResponseWriter, r *http.Request) {
+			err := json.NewEncoder(w).Encode(J{"data": J{"t0": J{
+				"identifier": "ENG-1",
+				"title":      "title",
+				"canceledAt": time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+				"state":      J{"name": "Canceled", "type": "canceled"},
+				"labels":     J{"nodes": []J{{"name": "Epic"}}},
+			}}})
+			require.NoError(t, err)
+		})
+
+		ticket, err := l.Get(context.Background(), "ENG-1")
+		require.NoError(t, err)
+
+		assert.Equal(t, task.Ticket{
+			ID:       "ENG-1",
+			Name:     "title",
+			ClosedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			Type:     task.TypeEpic,
+			TypeRaw:  "Epic",
+		}, utcTimes([]task.Ticket{ticket})[0])
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		l := newLinear(t, func(w http.ResponseWriter, r *http.Request) {
+			err := json.NewEncoder(w).Encode(J{
+				"data":   J{"t0": nil},
+				"errors": []J{{"message": "Entity not found: Issue", "path": []string{"t0"}}},
+			})
+			require.NoError(t, err)
+		})
+
+		_, err := l.Get(context.Background(), "ENG-1")
+		assert.EqualError(t, err, "linear returned error: Entity not found: Issue")
+	})
+
+	t.Run("query failed", func(t *testing.T) {
+		l := newLinear(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			err := json.NewEncoder(w).Encode(J{"errors": []J{{"message": "Authentication required"}}})
+			require.NoError(t, err)
+		})
+
+		_, err := l.Get(context.Background(), "ENG-1")
+		assert.EqualError(t, err, "linear returned error: Authentication required")
+	})
+}
+
+func TestNewLinear_Unauthorized(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte(`{"errors": [{"message": "Authentication required, not authenticated"}]}`))
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	_, err := NewLinear(context.Background(), LinearParams{BaseURL: ts.URL, Token: "invalid", HTTPClient: *ts.Client()})
+	assert.EqualError(t, err, "ping linear: Authentication required, not authenticated")
+}
+
+func newLinear(t *testing.T, h http.HandlerFunc) *Linear {
+	t.Helper()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/graphql", r.URL.Path)
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "lin_api_abacaba", r.Header.Get("Authorization"))
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		if strings.Contains(string(b), "viewer") {
+			_, err = w.Write([]byte(`{"data": {"viewer": {"id": "user-id"}}}`))
+			require.NoError(t, err)
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(b))
+		h(w, r)
+	}))
+	t.Cleanup(ts.Close)
+
+	svc, err := NewLinear(context.Background(), LinearParams{BaseURL: ts.URL, Token: "lin_api_abacaba", HTTPClient: *ts.Client()})
+	require.NoError(t, err)
+
+	return svc
+}
@@ -0,0 +1,271 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Semior001/releaseit/app/task"
+	"github.com/go-pkgz/requester"
+	"github.com/go-pkgz/requester/middleware"
+	"github.com/go-pkgz/requester/middleware/logger"
+	"github.com/samber/lo"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// youTrackIssueFields is the set of issue fields requested from youtrack.
+	youTrackIssueFields = "idReadable,summary,description,resolved," +
+		"reporter(login,email),parent(issues(idReadable)),customFields(name,value(login,email,name))"
+	// youTrackBatchSize is the maximum number of issues in a single query,
+	// to keep the length of the request URL within limits of the server.
+	youTrackBatchSize = 50
+	// youTrackConcurrency is the maximum number of batches requested at once.
+	youTrackConcurrency = 5
+)
+
+// YouTrack is a YouTrack task tracker engine.
+type YouTrack struct {
+	cl *http.Client
+	YouTrackParams
+}
+
+// YouTrackParams is a set of parameters for YouTrack engine.
+type YouTrackParams struct {
+	BaseURL    string
+	Token      string // permanent token of the user
+	HTTPClient http.Client
+	// names of custom fields with the assignee and the type
+	// of the issue, "Assignee" and "Type" by default
+	AssigneeField string
+	TypeField     string
+}
+
+// NewYouTrack creates a new YouTrack engine and checks the connection to it.
+func NewYouTrack(ctx context.Context, params YouTrackParams) (*YouTrack, error) {
+	if params.AssigneeField == "" {
+		params.AssigneeField = "Assignee"
+	}
+
+	if params.TypeField == "" {
+		params.TypeField = "Type"
+	}
+
+	params.BaseURL = strings.TrimSuffix(params.BaseURL, "/")
+
+	rq := requester.New(params.HTTPClient,
+		middleware.Header("Authorization", "Bearer "+params.Token),
+		middleware.Header("Accept", "application/json"),
+		logger.New(logger.Func(log.Printf), logger.Prefix("[DEBUG]")).Middleware,
+	)
+
+	svc := &YouTrack{cl: rq.Client(), YouTrackParams: params}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultSetupTimeout)
+	defer cancel()
+
+	var me struct {
+		Login string `json:"login"`
+	}
+	if err := svc.get(ctx, "/api/users/me?fields=login", &me); err != nil {
+		return nil, fmt.Errorf("ping youtrack: %w", err)
+	}
+
+	return svc, nil
+}
+
+// List lists tasks by their readable IDs in batches, non-existing tasks are omitted.
+func (y *YouTrack) List(ctx context.Context, ids []string) ([]task.Ticket, error) {
+	batches := lo.Chunk(ids, youTrackBatchSize)
+	results := make([][]task.Ticket, len(batches))
+
+	ewg, ectx := errgroup.WithContext(ctx)
+	ewg.SetLimit(youTrackConcurrency)
+	for idx, batch := range batches {
+		idx, batch := idx, batch
+		ewg.Go(func() (err error) {
+			results[idx], err = y.listBatch(ectx, batch)
+			return err
+		})
+	}
+
+	if err := ewg.Wait(); err != nil {
+		return nil, err
+	}
+
+	return lo.Flatten(results), nil
+}
+
+// listBatch requests all issues of the batch in a single query.
+func (y *YouTrack) listBatch(ctx context.Context, ids []string) ([]task.Ticket, error) {
+	q := url.Values{}
+	q.Set("query", "issue id: "+strings.Join(ids, ", "))
+	q.Set("fields", youTrackIssueFields)
+	q.Set("$top", strconv.Itoa(len(ids)))
+
+	var issues []youTrackIssue
+	if err := y.get(ctx, "/api/issues?"+q.Encode(), &issues); err != nil {
+		return nil, fmt.Errorf("youtrack returned error: %w", err)
+	}
+
+	return lo.Map(issues, func(issue youTrackIssue, _ int) task.Ticket {
+		return y.transformIssue(issue)
+	}), nil
+}
+
+// Get returns a single task by its readable ID.
+func (y *YouTrack) Get(ctx context.Context, id string) (task.Ticket, error) {
+	q := url.Values{}
+	q.Set("fields", youTrackIssueFields)
+
+	var issue youTrackIssue
+	if err := y.get(ctx, "/api/issues/"+url.PathEscape(id)+"?"+q.Encode(), &issue); err != nil {
+		return task.Ticket{}, fmt.Errorf("youtrack returned error: %w", err)
+	}
+
+	return y.transformIssue(issue), nil
+}
+
+func (y *YouTrack) get(ctx context.Context, path string, resp any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, y.BaseURL+path, http.NoBody)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	r, err := y.cl.Do(req)
+	if err != nil {
+		return fmt.Errorf("do request: %w", err)
+	}
+	defer func() {
+		if err = r.Body.Close(); err != nil {
+			log.Printf("[WARN] can't close response body, %s", err)
+		}
+	}()
+
+	if r.StatusCode != http.StatusOK {
+		var body struct {
+			Error       string `json:"error"`
+			Description string `json:"error_description"`
+		}
+
+		b, _ := io.ReadAll(io.LimitReader(r.Body, 1024))
+		if err = json.Unmarshal(b, &body); err == nil && body.Description != "" {
+			return fmt.Errorf("unexpected status code: %d, %s: %s", r.StatusCode, body.Error, body.Description)
+		}
+
+		return fmt.Errorf("unexpected status code: %d, message: %q", r.StatusCode, b)
+	}
+
+	if err = json.NewDecoder(r.Body).Decode(resp); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+
+	return nil
+}
+
+type youTrackUser struct {
+	Login string `json:"login"`
+	Email string `json:"email"`
+}
+
+type youTrackIssue struct {
+	IDReadable  string        `json:"idReadable"`
+	Summary     string        `json:"summary"`
+	Description string        `json:"description"`
+	Resolved    *int64        `json:"resolved"` // unix timestamp in milliseconds
+	Reporter    *youTrackUser `json:"reporter"`
+	Parent      *struct {
+		Issues []struct {
+			IDReadable string `json:"idReadable"`
+		} `json:"issues"`
+	} `json:"parent"`
+	CustomFields []struct {
+		Name string `json:"name"`
+		// value differs by the type of the field, it might be
+		// a user, an enum element, an array of them or a scalar
+		Value json.RawMessage `json:"value"`
+	} `json:"customFields"`
+}
+
+// youTrackFieldValue contains attributes of the custom field value,
+// which are used by the engine.
+type youTrackFieldValue struct {
+	youTrackUser
+	Name string `json:"name"`
+}
+
+var youTrackTypeMapping = map[string]task.Type{
+	"epic": task.TypeEpic,
+	"bug":  task.TypeTask, "task": task.TypeTask, "feature": task.TypeTask, "user story": task.TypeTask,
+	"cosmetics": task.TypeTask, "exception": task.TypeTask,
+	"usability problem": task.TypeTask, "performance problem": task.TypeTask,
+	"subtask": task.TypeSubtask, "sub-task": task.TypeSubtask,
+}
+
+func (y *YouTrack) transformIssue(issue youTrackIssue) task.Ticket {
+	u, _ := url.JoinPath(y.BaseURL, "issue", issue.IDReadable)
+
+	ticket := task.Ticket{
+		ID:     issue.IDReadable,
+		URL:    u,
+		Name:   issue.Summary,
+		Body:   issue.Description,
+		Author: y.transformUser(issue.Reporter),
+	}
+
+	if issue.Resolved != nil {
+		ticket.ClosedAt = time.UnixMilli(*issue.Resolved)
+	}
+
+	if issue.Parent != nil && len(issue.Parent.Issues) > 0 {
+		ticket.ParentID = issue.Parent.Issues[0].IDReadable
+	}
+
+	for _, field := range issue.CustomFields {
+		val, ok := y.fieldValue(field.Value)
+		if !ok {
+			continue
+		}
+
+		switch field.Name {
+		case y.AssigneeField:
+			ticket.Assignee = y.transformUser(&val.youTrackUser)
+		case y.TypeField:
+			ticket.TypeRaw = val.Name
+			ticket.Type = youTrackTypeMapping[strings.ToLower(val.Name)]
+		}
+	}
+
+	return ticket
+}
+
+// fieldValue decodes the value of the custom field, if the field
+// contains multiple values, the first one is returned.
+func (y *YouTrack) fieldValue(raw json.RawMessage) (youTrackFieldValue, bool) {
+	var val youTrackFieldValue
+	if err := json.Unmarshal(raw, &val); err == nil {
+		return val, true
+	}
+
+	var vals []youTrackFieldValue
+	if err := json.Unmarshal(raw, &vals); err == nil && len(vals) > 0 {
+		return vals[0], true
+	}
+
+	return youTrackFieldValue{}, false
+}
+
+func (y *YouTrack) transformUser(user *youTrackUser) task.User {
+	if user == nil {
+		return task.User{}
+	}
+
+	return task.User{Username: user.Login, Email: user.Email}
+}
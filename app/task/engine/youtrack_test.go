@@ -0,0 +1,199 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Semior001/releaseit/app/task"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestYouTrack_List(t *testing.T) {
+	y := newYouTrack(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/issues", r.URL.Path)
+		assert.Equal(t, "issue id: PRJ-1, PRJ-2", r.URL.Query().Get("query"))
+		assert.Equal(t, youTrackIssueFields, r.URL.Query().Get("fields"))
+		assert.Equal(t, "2", r.URL.Query().Get("$top"))
+
+		err := json.NewEncoder(w).Encode([]J{
+			{
+				"idReadable":  "PRJ-1",
+				"summary":     "summary",
+				"description": "description",
+				"resolved":    time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli(),
+				"reporter":    J{"login": "reporter", "email": "reporter@example.com"},
+				"parent":      J{"issues": []J{}},
+				"customFields": []J{
+					{"name": "Type", "value": J{"name": "Epic"}},
+					{"name": "Assignee", "value": nil},
+					{"name": "Estimation", "value": 42},
+				},
+			},
+			{
+				"idReadable":  "PRJ-2",
+				"summary":     "summary-1",
+				"description": "description-1",
+				"resolved":    nil,
+				"reporter":    J{"login": "reporter1", "email": "reporter1@example.com"},
+				"parent":      J{"issues": []J{{"idReadable": "PRJ-1"}}},
+				"customFields": []J{
+					{"name": "Type", "value": J{"name": "Bug"}},
+					{"name": "Assignee", "value": J{"login": "assignee", "email": "assignee@example.com"}},
+				},
+			},
+		})
+		require.NoError(t, err)
+	})
+
+	tickets, err := y.List(context.Background(), []string{"PRJ-1", "PRJ-2"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []task.Ticket{
+		{
+			ID:       "PRJ-1",
+			URL:      y.BaseURL + "/issue/PRJ-1",
+			Name:     "summary",
+			Body:     "description",
+			ClosedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			Author:   task.User{Username: "reporter", Email: "reporter@example.com"},
+			Type:     task.TypeEpic,
+			TypeRaw:  "Epic",
+		},
+		{
+			ID:       "PRJ-2",
+			ParentID: "PRJ-1",
+			URL:      y.BaseURL + "/issue/PRJ-2",
+			Name:     "summary-1",
+			Body:     "description-1",
+			Author:   task.User{Username: "reporter1", Email: "reporter1@example.com"},
+			Assignee: task.User{Username: "assignee", Email: "assignee@example.com"},
+			Type:     task.TypeTask,
+			TypeRaw:  "Bug",
+		},
+	}, utcTimes(tickets))
+}
+
+func TestYouTrack_List_batches(t *testing.T) {
+	ids := make([]string, youTrackBatchSize+1)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("PRJ-%d", i)
+	}
+
+	var mu sync.Mutex
+	var sizes []int
+	y := newYouTrack(t, func(w http.ResponseWriter, r *http.Request) {
+		batch := strings.Split(strings.TrimPrefix(r.URL.Query().Get("query"), "issue id: "), ", ")
+		assert.Equal(t, strconv.Itoa(len(batch)), r.URL.Query().Get("$top"))
+
+		mu.Lock()
+		sizes = append(sizes, len(batch))
+		mu.Unlock()
+
+		require.NoError(t, json.NewEncoder(w).Encode(lo.Map(batch, func(id string, _ int) J {
+			return J{"idReadable": id, "summary": "summary"}
+		})))
+	})
+
+	tickets, err := y.List(context.Background(), ids)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int{youTrackBatchSize, 1}, sizes)
+	assert.Equal(t, ids, lo.Map(tickets, func(ticket task.Ticket, _ int) string { return ticket.ID }))
+}
+
+func TestYouTrack_Get(t *testing.T) {
+	t.Run("custom fields", func(t *testing.T) {
+		y := newYouTrack(t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/api/issues/PRJ-3", r.URL.Path)
+			assert.Equal(t, youTrackIssueFields, r.URL.Query().Get("fields"))
+
+			err := json.NewEncoder(w).Encode(J{
+				"idReadable": "PRJ-3",
+				"summary":    "summary",
+				"reporter":   J{"login": "reporter", "email": "reporter@example.com"},
+				"parent":     J{"issues": []J{{"idReadable": "PRJ-2"}}},
+				"customFields": []J{
+					{"name": "Kind", "value": J{"name": "Subtask"}},
+					{"name": "Developers", "value": []J{{"login": "dev1"}, {"login": "dev2"}}},
+				},
+			})
+			require.NoError(t, err)
+		})
+		y.TypeField, y.AssigneeField = "Kind", "Developers"
+
+		ticket, err := y.Get(context.Background(), "PRJ-3")
+		require.NoError(t, err)
+
+		assert.Equal(t, task.Ticket{
+			ID:       "PRJ-3",
+			ParentID: "PRJ-2",
+			URL:      y.BaseURL + "/issue/PRJ-3",
+			Name:     "summary",
+			Author:   task.User{Username: "reporter", Email: "reporter@example.com"},
+			Assignee: task.User{Username: "dev1"},
+			Type:     task.TypeSubtask,
+			TypeRaw:  "Subtask",
+		}, ticket)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		y := newYouTrack(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			err := json.NewEncoder(w).Encode(J{"error": "Not Found", "error_description": "Entity with id PRJ-4 not found"})
+			require.NoError(t, err)
+		})
+
+		_, err := y.Get(context.Background(), "PRJ-4")
+		assert.EqualError(t, err, "youtrack returned error: unexpected status code: 404, "+
+			"Not Found: Entity with id PRJ-4 not found")
+	})
+}
+
+func TestNewYouTrack_Unauthorized(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/users/me", r.URL.Path)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte(`{"error": "Unauthorized", "error_description": "Invalid token"}`))
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	_, err := NewYouTrack(context.Background(), YouTrackParams{BaseURL: ts.URL, Token: "invalid", HTTPClient: *ts.Client()})
+	assert.EqualError(t, err, "ping youtrack: unexpected status code: 401, Unauthorized: Invalid token")
+}
+
+func newYouTrack(t *testing.T, h http.HandlerFunc) *YouTrack {
+	t.Helper()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer perm:abacaba", r.Header.Get("Authorization"))
+		require.Equal(t, http.MethodGet, r.Method)
+
+		if r.URL.Path == "/api/users/me" {
+			_, err := w.Write([]byte(`{"login": "user"}`))
+			require.NoError(t, err)
+			return
+		}
+
+		h(w, r)
+	}))
+	t.Cleanup(ts.Close)
+
+	svc, err := NewYouTrack(context.Background(), YouTrackParams{
+		BaseURL:    ts.URL + "/",
+		Token:      "perm:abacaba",
+		HTTPClient: *ts.Client(),
+	})
+	require.NoError(t, err)
+
+	return svc
+}